@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"lib/internal/config"
+	"lib/internal/service"
+	"strings"
+)
+
+// verifyAudit walks the local audit chain and prints the first broken link.
+// It returns the process exit code.
+func verifyAudit(ctx context.Context, auditTrail *service.AuditTrail) int {
+	report, err := auditTrail.Verify(ctx)
+	if err != nil {
+		fmt.Printf("audit verification failed: %s\n", err)
+		return 2
+	}
+
+	if !report.OK() {
+		fmt.Printf("audit chain broken at seq %d: %s (%d records verified)\n", report.BrokenAt, report.Reason, report.Checked)
+		return 1
+	}
+
+	fmt.Printf("audit chain OK: %d records, %d checkpoints verified\n", report.Checked, report.Checkpoints)
+	return 0
+}
+
+// auditPolicy builds the service-level audit policy from configuration.
+func auditPolicy(next service.AuditClient, cfg *config.Config) *service.AuditPolicy {
+	defaults := auditRule(service.AuditRule{Enabled: true, SampleRate: 1, Fatal: true}, cfg.Audit.Policy.Default)
+
+	rules := make([]service.AuditRule, 0, len(cfg.Audit.Policy.Rules))
+	for _, r := range cfg.Audit.Policy.Rules {
+		rules = append(rules, auditRule(defaults, r))
+	}
+
+	return service.NewAuditPolicy(next, defaults, rules)
+}
+
+func auditRule(base service.AuditRule, r config.AuditRule) service.AuditRule {
+	rule := service.AuditRule{
+		Entity:     strings.ToUpper(r.Entity),
+		Action:     strings.ToUpper(r.Action),
+		Enabled:    base.Enabled,
+		SampleRate: base.SampleRate,
+		Fatal:      base.Fatal,
+	}
+
+	if r.Enabled != nil {
+		rule.Enabled = *r.Enabled
+	}
+
+	if r.SampleRate != nil {
+		rule.SampleRate = *r.SampleRate
+	}
+
+	if r.Fatal != nil {
+		rule.Fatal = *r.Fatal
+	}
+
+	return rule
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"lib/internal/bookio"
+	"lib/internal/config"
+	"lib/internal/domain"
+	"lib/internal/repository/psql"
+	"lib/internal/service"
+	"lib/migrations"
+	"lib/pkg/database"
+	"lib/pkg/lifecycle"
+	"os"
+	"strings"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	log "github.com/sirupsen/logrus"
+)
+
+// exportCommand runs the export subcommand. It needs nothing but the
+// database: it runs no migrations, sets up no tracing and does not dial the
+// remote audit service, since exports are only kept in the local audit
+// trail. It returns the process exit code.
+func exportCommand(cfg *config.Config, auditSecret []byte, args []string) int {
+	ctx := context.Background()
+
+	db, err := openDB()
+	if err != nil {
+		log.Error(err)
+		return lifecycle.ExitStartupError
+	}
+	defer db.Close()
+
+	if err := database.NewMigrationsCheck(db, migrations.FS).Check(ctx); err != nil {
+		log.Error(err)
+		return lifecycle.ExitStartupError
+	}
+
+	auditTrail := service.NewAuditTrail(psql.NewAudit(db), offlineAudit{}, auditSecret, cfg.Audit.CheckpointInterval)
+	books := service.NewBooks(psql.NewBooks(db), auditPolicy(auditTrail, cfg))
+
+	return exportCatalog(ctx, books, args)
+}
+
+// offlineAudit stands in for the remote audit service in commands that
+// only record actions the remote service does not know.
+type offlineAudit struct{}
+
+func (offlineAudit) SendLogRequest(context.Context, audit.LogItem) error {
+	return errors.New("remote audit service is not available to this command")
+}
+
+// exportCatalog writes the catalog to a file, as GET /books/export does.
+// It returns the process exit code.
+func exportCatalog(ctx context.Context, books *service.Books, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+
+	format := fs.String("format", bookio.FormatCSV, "csv, ndjson, marc or marcxml")
+	output := fs.String("o", "", "output file, gzip-compressed when it ends in .gz (required)")
+	name := fs.String("name", "", "only books whose name contains this")
+	author := fs.String("author", "", "only books whose author contains this")
+	publisherID := fs.Int64("publisher-id", 0, "only books of this publisher")
+	minRating := fs.Int64("min-rating", 0, "only books rated at least this")
+	publishedFrom := fs.Int64("published-from", 0, "only books published in or after this year")
+	publishedTo := fs.Int64("published-to", 0, "only books published in or before this year")
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "export: -o is required")
+		fs.Usage()
+		return 2
+	}
+
+	filter := domain.BookFilter{Name: *name, Author: *author}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "publisher-id":
+			filter.PublisherID = publisherID
+		case "min-rating":
+			filter.MinRating = minRating
+		case "published-from":
+			filter.PublishedFrom = publishedFrom
+		case "published-to":
+			filter.PublishedTo = publishedTo
+		}
+	})
+
+	n, err := writeExport(ctx, books, filter, *format, *output)
+	if err != nil {
+		os.Remove(*output)
+		fmt.Fprintf(os.Stderr, "export failed: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("exported %d books to %s\n", n, *output)
+	return 0
+}
+
+func writeExport(ctx context.Context, books *service.Books, filter domain.BookFilter, format, path string) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	var (
+		out io.Writer = file
+		gz  *gzip.Writer
+	)
+	if strings.HasSuffix(path, ".gz") {
+		gz = gzip.NewWriter(file)
+		out = gz
+	}
+
+	enc, err := bookio.NewWriter(format, out)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	err = books.Export(ctx, filter, func(book domain.Book) error {
+		n++
+		return enc.Write(book)
+	})
+	if err != nil {
+		return n, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return n, err
+	}
+
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return n, err
+		}
+	}
+
+	return n, file.Close()
+}
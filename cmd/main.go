@@ -1,22 +1,37 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"lib/internal/config"
+	"lib/internal/domain"
+	"lib/internal/metrics"
+	"lib/internal/notify"
 	"lib/internal/repository/psql"
 	"lib/internal/service"
 	grpc_client "lib/internal/transport/grpc"
 	"lib/internal/transport/rest"
+	"lib/migrations"
+	"lib/pkg/blob"
+	"lib/pkg/cron"
 	"lib/pkg/database"
 	"lib/pkg/hash"
+	"lib/pkg/lifecycle"
+	"lib/pkg/scheduler"
+	"lib/pkg/tracing"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
@@ -31,58 +46,312 @@ func init() {
 }
 
 func main() {
+	os.Exit(run())
+}
+
+func run() int {
 	cfg, err := config.NewConfig(CONFIG_DIR, CONFIG_FILE)
 	if err != nil {
-		log.Fatal(err)
+		log.Error(err)
+		return lifecycle.ExitStartupError
 	}
 
 	if err := godotenv.Load(); err != nil {
-		log.Fatalf("error loading.env file: %s", err.Error())
+		log.Errorf("error loading.env file: %s", err.Error())
+		return lifecycle.ExitStartupError
 	}
 
-	db, err := database.NewPostgresConnection(
-		database.ConnectionInfo{
-			Name:     os.Getenv("DB_DBNAME"),
-			Port:     StringToInt(os.Getenv("DB_PORT")),
-			Host:     os.Getenv("DB_HOST"),
-			User:     os.Getenv("DB_NAME"),
-			Password: os.Getenv("DB_PASSWORD"),
-			SSLMode:  os.Getenv("DB_SSLMODE"),
-		},
-	)
+	auditSecret, err := auditSecret()
+	if err != nil {
+		log.Error(err)
+		return lifecycle.ExitStartupError
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		return exportCommand(cfg, auditSecret, os.Args[2:])
+	}
+
+	app := lifecycle.NewManager(cfg.Server.ShutdownTimeout)
+	fail := func(err error) int {
+		log.Error(err)
+		app.Shutdown()
+		return lifecycle.ExitStartupError
+	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		ServiceName: cfg.Tracing.ServiceName,
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		log.Fatal(err)
+		return fail(err)
+	}
+
+	app.OnShutdown("tracing", shutdownTracing)
+
+	db, err := openDB()
+	if err != nil {
+		return fail(err)
+	}
+
+	app.OnClose("postgres", db.Close)
+
+	if err := database.Migrate(db, migrations.FS); err != nil {
+		return fail(err)
 	}
 
-	defer db.Close()
 	hasher := hash.NewSHA1Hasher("salt")
 
 	auditService, err := grpc_client.NewClient(9000)
 	if err != nil {
-		log.Fatal(err)
+		return fail(err)
 	}
 
+	app.OnClose("audit grpc", auditService.CloseConnection)
+
+	registry := prometheus.NewRegistry()
+	appMetrics := metrics.New(registry)
+	appMetrics.RegisterDB("postgres", db)
+
+	auditRepo := psql.NewAudit(db)
+	auditTrail := service.NewAuditTrail(auditRepo, metrics.NewAuditClient(auditService, appMetrics), auditSecret, cfg.Audit.CheckpointInterval)
+
+	if len(os.Args) > 1 && os.Args[1] == "verify" {
+		code := verifyAudit(context.Background(), auditTrail)
+		app.Shutdown()
+		return code
+	}
+
+	auditClient := auditPolicy(auditTrail, cfg)
+
 	booksRepo := psql.NewBooks(db)
-	booksService := service.NewBooks(booksRepo, auditService)
+	booksService := service.NewBooks(booksRepo, auditClient)
+
+	coverStore, err := newBlobStore(cfg)
+	if err != nil {
+		return fail(err)
+	}
+
+	coversRepo := psql.NewCovers(db)
+	coversService := service.NewCovers(coversRepo, coverStore, auditClient)
+
+	importsRepo := psql.NewImports(db)
+	importsService := service.NewImports(booksRepo, importsRepo, auditClient, cfg.Books.ImportBatchSize)
+	if err := importsService.Recover(context.Background()); err != nil {
+		return fail(err)
+	}
+	app.OnShutdown("imports", importsService.Shutdown)
+
+	authorsRepo := psql.NewAuthors(db)
+	authorsService := service.NewAuthors(authorsRepo, auditClient)
+
+	publishersRepo := psql.NewPublishers(db)
+	publishersService := service.NewPublishers(publishersRepo, auditClient)
+
+	finePolicy := domain.FinePolicy{Rates: make(map[string]domain.FineRate, len(cfg.Fines.Rates))}
+	for itemType, rate := range cfg.Fines.Rates {
+		finePolicy.Rates[itemType] = domain.FineRate{PerDay: rate.PerDay, Cap: rate.Cap}
+	}
+
+	loanPolicy := domain.LoanPolicy{
+		Periods:      cfg.Circulation.LoanPeriods,
+		MaxRenewals:  cfg.Circulation.MaxRenewals,
+		PickupPeriod: cfg.Circulation.PickupPeriod,
+		MaxBalance:   cfg.Fines.MaxBalance,
+		Fines:        finePolicy,
+	}
+	notifier := newNotifier(cfg)
+
+	itemsRepo := psql.NewItems(db)
+	itemsService := service.NewItems(itemsRepo, auditClient, notifier, loanPolicy)
+
+	loansRepo := psql.NewLoans(db)
+	loansService := service.NewLoans(loansRepo, auditClient, notifier, loanPolicy)
+
+	holdsRepo := psql.NewHolds(db)
+	holdsService := service.NewHolds(holdsRepo, auditClient, notifier, loanPolicy)
+
+	ledgerRepo := psql.NewLedger(db)
+	ledgerService := service.NewLedger(ledgerRepo, auditClient, finePolicy)
 
 	usersRepo := psql.NewUsers(db)
 	tokenRepo := psql.NewToken(db)
 
-	usersService := service.NewUsers(usersRepo, tokenRepo, hasher, auditService, []byte(os.Getenv("HASH_SECRET")), cfg.Auth.TokenTTL)
+	usersService := service.NewUsers(usersRepo, tokenRepo, hasher, auditClient, []byte(os.Getenv("HASH_SECRET")), cfg.Auth.TokenTTL)
+
+	jobs, err := scheduledJobs(cfg, map[string]func(ctx context.Context) error{
+		"purge_refresh_tokens": func(ctx context.Context) error {
+			purged, err := usersService.PurgeExpiredSessions(ctx)
+			if purged > 0 {
+				log.WithField("sessions", purged).Info("purged expired sessions")
+			}
+			return err
+		},
+		"purge_trash": func(ctx context.Context) error {
+			ids, err := booksService.PurgeTrash(ctx, cfg.Books.TrashRetention)
+			if len(ids) > 0 {
+				coversService.Remove(ctx, ids...)
+				log.WithField("books", len(ids)).Info("purged trash")
+			}
+			return err
+		},
+		"recompute_ratings": booksService.RecomputeRatings,
+		"expire_holds": func(ctx context.Context) error {
+			expired, err := holdsService.ExpireReady(ctx)
+			if expired > 0 {
+				log.WithField("holds", expired).Info("expired holds")
+			}
+			return err
+		},
+		"accrue_fines": func(ctx context.Context) error {
+			charged, err := ledgerService.Accrue(ctx)
+			if charged > 0 {
+				log.WithField("fines", charged).Info("accrued fines")
+			}
+			return err
+		},
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	instance, _ := os.Hostname()
+	jobRunsRepo := psql.NewJobRuns(db, instance)
+	jobRunsService := service.NewJobRuns(jobRunsRepo)
+
+	jobScheduler := scheduler.New(database.NewAdvisoryLock(db, "lib:scheduler"), jobRunsRepo, appMetrics, jobs...)
+	if cfg.Scheduler.Enabled {
+		jobScheduler.Start()
+	}
+	app.OnShutdown("scheduler", jobScheduler.Stop)
 
-	handler := rest.NewHandler(booksService, usersService)
+	handler := rest.NewHandler(booksService, authorsService, publishersService, itemsService, loansService, holdsService, ledgerService, importsService, coversService, usersService, auditTrail, jobRunsService, appMetrics)
+	handler.SetPublicURL(cfg.Server.PublicURL)
+	handler.AddHealthCheck(database.NewPingCheck(db), cfg.Health.DatabaseTimeout)
+	handler.AddHealthCheck(auditService, cfg.Health.AuditTimeout)
+	handler.AddHealthCheck(database.NewMigrationsCheck(db, migrations.FS), cfg.Health.MigrationsTimeout)
 
 	srv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Server.Port),
 		Handler: handler.InitRouter(),
 	}
 
-	log.Info("SERVER STARTED AT")
+	app.OnShutdown("http server", srv.Shutdown)
+	app.OnShutdown("readiness", func(ctx context.Context) error {
+		handler.SetNotReady()
+
+		select {
+		case <-time.After(cfg.Server.DrainDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	log.WithField("addr", srv.Addr).Info("SERVER STARTED")
 
-	if err := srv.ListenAndServe(); err != nil {
-		log.Fatal(err)
+	return app.Run(func() error {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	})
+}
+
+// auditSecret returns the key the audit checkpoints are signed with.
+// Without one anyone with database access could forge them, so it is
+// required.
+func auditSecret() ([]byte, error) {
+	secret := os.Getenv("AUDIT_SECRET")
+	if secret == "" {
+		return nil, errors.New("AUDIT_SECRET is not set")
 	}
+	return []byte(secret), nil
+}
+
+// openDB connects to the Postgres database named in the environment.
+func openDB() (*sql.DB, error) {
+	return database.NewPostgresConnection(
+		database.ConnectionInfo{
+			Name:     os.Getenv("DB_DBNAME"),
+			Port:     StringToInt(os.Getenv("DB_PORT")),
+			Host:     os.Getenv("DB_HOST"),
+			User:     os.Getenv("DB_NAME"),
+			Password: os.Getenv("DB_PASSWORD"),
+			SSLMode:  os.Getenv("DB_SSLMODE"),
+		},
+	)
+}
+
+// newBlobStore opens the store configured for cover images. S3 credentials
+// come from the environment.
+func newBlobStore(cfg *config.Config) (service.BlobStore, error) {
+	switch cfg.Covers.Backend {
+	case "", "local":
+		return blob.NewLocal(cfg.Covers.Dir)
+	case "s3":
+		return blob.NewS3(blob.S3Options{
+			Endpoint:  cfg.Covers.S3.Endpoint,
+			Region:    cfg.Covers.S3.Region,
+			Bucket:    cfg.Covers.S3.Bucket,
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: cfg.Covers.S3.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown covers backend %q", cfg.Covers.Backend)
+	}
+}
+
+// newNotifier returns the configured notification channel, falling back
+// to the log.
+func newNotifier(cfg *config.Config) service.Notifier {
+	if cfg.Notifications.WebhookURL == "" {
+		return notify.Log{}
+	}
+	return notify.NewWebhook(cfg.Notifications.WebhookURL, cfg.Notifications.Timeout)
+}
+
+// scheduledJobs pairs the configured schedules with their tasks. Tasks
+// without a schedule are left out.
+func scheduledJobs(cfg *config.Config, tasks map[string]func(ctx context.Context) error) ([]scheduler.Job, error) {
+	for name := range cfg.Scheduler.Jobs {
+		if _, ok := tasks[name]; !ok {
+			return nil, fmt.Errorf("scheduler: unknown job %q", name)
+		}
+	}
+
+	names := make([]string, 0, len(tasks))
+	for name := range tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	jobs := make([]scheduler.Job, 0, len(names))
+	for _, name := range names {
+		jobCfg, ok := cfg.Scheduler.Jobs[name]
+		if !ok {
+			log.WithField("job", name).Warn("job has no schedule and will not run")
+			continue
+		}
+
+		schedule, err := cron.Parse(jobCfg.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: job %q: %w", name, err)
+		}
+
+		jobs = append(jobs, scheduler.Job{
+			Name:     name,
+			Schedule: schedule,
+			Timeout:  jobCfg.Timeout,
+			Jitter:   jobCfg.Jitter,
+			Run:      tasks[name],
+		})
+	}
+
+	return jobs, nil
 }
 
 func StringToInt(s string) int {
@@ -0,0 +1,176 @@
+package bookio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"lib/internal/domain"
+	"lib/pkg/marc"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// leader is the leader of a new language-material monograph record. Record
+// length and base address are filled in by binary encoders.
+const leader = "00000nam a2200000 i 4500"
+
+// relatorTerms are the MARC relator terms of non-author roles.
+var relatorTerms = map[string]string{
+	domain.AuthorRoleEditor:     "editor",
+	domain.AuthorRoleTranslator: "translator",
+}
+
+// MARCRecord describes a book as a MARC 21 bibliographic record:
+//
+//	001     book ID
+//	008     publication year
+//	020 $a  ISBN-13 and ISBN-10
+//	100 $a  first author
+//	700 $a  other contributors, with the role in $e
+//	245 $a  title
+//	264 $b  publisher, $c publication date
+func MARCRecord(book domain.Book) marc.Record {
+	rec := marc.Record{Leader: leader}
+
+	rec.AddControl("001", strconv.Itoa(book.ID))
+	rec.AddControl("008", fixedField008(book.PublishedOn))
+
+	rec.AddData("020", ' ', ' ', "a", book.ISBN13)
+	rec.AddData("020", ' ', ' ', "a", book.ISBN10)
+
+	main := -1
+	for i, a := range book.Authors {
+		if a.Role == domain.AuthorRoleAuthor {
+			main = i
+			break
+		}
+	}
+
+	if main >= 0 {
+		rec.AddData("100", '1', ' ', "a", book.Authors[main].Name)
+	} else if len(book.Authors) == 0 {
+		rec.AddData("100", '1', ' ', "a", book.Author)
+	}
+
+	titleInd1 := byte('0')
+	if main >= 0 || len(book.Authors) == 0 && book.Author != "" {
+		titleInd1 = '1'
+	}
+	rec.AddData("245", titleInd1, '0', "a", book.Name)
+
+	publishedOn := ""
+	if book.PublishedOn != nil {
+		publishedOn = book.PublishedOn.String()
+	}
+	rec.AddData("264", ' ', '1', "b", book.PublisherName, "c", publishedOn)
+
+	for i, a := range book.Authors {
+		if i == main {
+			continue
+		}
+		rec.AddData("700", '1', ' ', "a", a.Name, "e", relatorTerms[a.Role])
+	}
+
+	return rec
+}
+
+// fixedField008 builds the 40-character 008 field, filling in only the
+// publication date; everything else is left blank or unknown.
+func fixedField008(d *domain.PartialDate) string {
+	dateType, date1 := "n", "uuuu"
+	if d != nil {
+		dateType, date1 = "s", fmt.Sprintf("%04d", d.Year)
+	}
+
+	return fmt.Sprintf("%-6s%s%s%-4s%-3s%-17s%-3s%-2s", "", dateType, date1, "", "xx", "", "und", " d")
+}
+
+// yearPattern finds the year in a publication date such as "c2001." or
+// "[2001?]".
+var yearPattern = regexp.MustCompile(`\d{4}`)
+
+// BookFromMARC reads a book payload from a MARC 21 bibliographic record,
+// the inverse of MARCRecord: title from 245, author from 100 or else the
+// first 700, ISBN from the first 020 and publisher and year from 264, or
+// 260 in older records. Contributors are matched by name on import.
+func BookFromMARC(rec marc.Record) domain.CreateBookInput {
+	var inp domain.CreateBookInput
+
+	if fields := rec.Fields("245"); len(fields) > 0 {
+		title := marc.TrimPunctuation(fields[0].Subfield("a"))
+		if sub := marc.TrimPunctuation(fields[0].Subfield("b")); sub != "" {
+			title += " : " + sub
+		}
+		inp.Name = title
+	}
+
+	if fields := rec.Fields("100"); len(fields) > 0 {
+		inp.Author = marc.TrimPunctuation(fields[0].Subfield("a"))
+	} else if fields := rec.Fields("700"); len(fields) > 0 {
+		inp.Author = marc.TrimPunctuation(fields[0].Subfield("a"))
+	}
+
+	if fields := rec.Fields("020"); len(fields) > 0 {
+		inp.ISBN = marc.ISBN(fields[0])
+	}
+
+	imprint := publicationField(rec)
+	inp.PublisherName = marc.TrimPunctuation(imprint.Subfield("b"))
+
+	if m := yearPattern.FindString(imprint.Subfield("c")); m != "" {
+		year, _ := strconv.Atoi(m)
+		inp.PublishedOn = &domain.PartialDate{Year: year}
+	}
+
+	return inp
+}
+
+// publicationField returns the 264 publication statement, falling back to
+// the 260 imprint.
+func publicationField(rec marc.Record) marc.DataField {
+	for _, f := range rec.Fields("264") {
+		if f.Ind2 == "1" {
+			return f
+		}
+	}
+
+	if fields := rec.Fields("260"); len(fields) > 0 {
+		return fields[0]
+	}
+
+	return marc.DataField{}
+}
+
+// marcSource is the record reader of one of the MARC encodings.
+type marcSource interface {
+	Next() (marc.Record, error)
+}
+
+// MARCReader reads books from ISO 2709 or MARCXML records.
+type MARCReader struct {
+	src marcSource
+}
+
+func NewMARCReader(r io.Reader) *MARCReader {
+	return &MARCReader{src: marc.NewReader(r)}
+}
+
+func NewMARCXMLReader(r io.Reader) *MARCReader {
+	return &MARCReader{src: marc.NewXMLReader(r)}
+}
+
+func (m *MARCReader) Next() (domain.CreateBookInput, error) {
+	rec, err := m.src.Next()
+	if err != nil {
+		if errors.Is(err, marc.ErrFormat) {
+			return domain.CreateBookInput{}, domain.NewValidationError(domain.FieldError{
+				Field:   "record",
+				Message: strings.TrimPrefix(err.Error(), marc.ErrFormat.Error()+": "),
+			})
+		}
+		return domain.CreateBookInput{}, err
+	}
+
+	return BookFromMARC(rec), nil
+}
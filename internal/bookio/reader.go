@@ -0,0 +1,193 @@
+// Package bookio reads and writes books in the interchange formats used for
+// bulk import and export.
+package bookio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"lib/internal/domain"
+	"strconv"
+	"strings"
+)
+
+// maxLineSize bounds a single NDJSON line.
+const maxLineSize = 1 << 20
+
+// Reader yields one book payload per data row. Rows that cannot be decoded
+// are reported as a *domain.ValidationError and reading may continue; any
+// other error is fatal. Next returns io.EOF after the last row.
+type Reader interface {
+	Next() (domain.CreateBookInput, error)
+}
+
+// NewReader returns a Reader for the given import format.
+func NewReader(format string, r io.Reader) (Reader, error) {
+	switch format {
+	case domain.ImportFormatCSV:
+		return NewCSVReader(r)
+	case domain.ImportFormatNDJSON:
+		return NewNDJSONReader(r), nil
+	case domain.ImportFormatMARC:
+		return NewMARCReader(r), nil
+	case domain.ImportFormatMARCXML:
+		return NewMARCXMLReader(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// csvColumns are the columns a CSV import may have, in any order. Only
+// name is mandatory. The id column written by CSVWriter is accepted and
+// ignored.
+var csvColumns = []string{"name", "author", "isbn", "published_on", "publisher_id", "rating"}
+
+// CSVReader reads books from CSV with a header row naming the columns.
+type CSVReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func NewCSVReader(r io.Reader) (*CSVReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("csv: missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "id" {
+			continue
+		}
+		if !isCSVColumn(name) {
+			return nil, fmt.Errorf("csv: unknown column %q, expected %s", name, strings.Join(csvColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("csv: duplicate column %q", name)
+		}
+		columns[name] = i
+	}
+
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("csv: missing column \"name\"")
+	}
+
+	return &CSVReader{r: cr, columns: columns}, nil
+}
+
+func isCSVColumn(name string) bool {
+	for _, c := range csvColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CSVReader) Next() (domain.CreateBookInput, error) {
+	var inp domain.CreateBookInput
+
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return inp, domain.NewValidationError(domain.FieldError{Field: "row", Message: parseErr.Err.Error()})
+		}
+		return inp, err
+	}
+
+	get := func(column string) string {
+		if i, ok := c.columns[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	fields := make([]domain.FieldError, 0)
+
+	inp.Name = get("name")
+	inp.Author = get("author")
+	inp.ISBN = get("isbn")
+
+	if s := get("published_on"); s != "" {
+		d, err := domain.ParsePartialDate(s)
+		if err != nil {
+			fields = append(fields, domain.FieldError{Field: "published_on", Message: err.Error()})
+		} else {
+			inp.PublishedOn = &d
+		}
+	}
+
+	if s := get("publisher_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			fields = append(fields, domain.FieldError{Field: "publisher_id", Message: "must be an integer"})
+		} else {
+			inp.PublisherID = &id
+		}
+	}
+
+	if s := get("rating"); s != "" {
+		rating, err := strconv.Atoi(s)
+		if err != nil {
+			fields = append(fields, domain.FieldError{Field: "rating", Message: "must be an integer"})
+		} else {
+			inp.Rating = &rating
+		}
+	}
+
+	if len(fields) > 0 {
+		return inp, domain.NewValidationError(fields...)
+	}
+
+	return inp, nil
+}
+
+// NDJSONReader reads books from newline-delimited JSON, one book payload
+// per line as accepted by POST /books. Blank lines are skipped.
+type NDJSONReader struct {
+	s *bufio.Scanner
+}
+
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	return &NDJSONReader{s: s}
+}
+
+func (n *NDJSONReader) Next() (domain.CreateBookInput, error) {
+	var inp domain.CreateBookInput
+
+	for n.s.Scan() {
+		line := bytes.TrimSpace(n.s.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&inp); err != nil {
+			return inp, domain.NewValidationError(domain.FieldError{Field: "row", Message: err.Error()})
+		}
+
+		return inp, nil
+	}
+
+	if err := n.s.Err(); err != nil {
+		return inp, err
+	}
+
+	return inp, io.EOF
+}
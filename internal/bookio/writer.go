@@ -0,0 +1,164 @@
+package bookio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lib/internal/domain"
+	"lib/pkg/marc"
+	"strconv"
+)
+
+// Export formats.
+const (
+	FormatCSV     = domain.ImportFormatCSV
+	FormatNDJSON  = domain.ImportFormatNDJSON
+	FormatMARC    = domain.ImportFormatMARC
+	FormatMARCXML = domain.ImportFormatMARCXML
+)
+
+// Writer encodes books one at a time. Close flushes buffered output and
+// ends the document; it does not close the underlying writer.
+type Writer interface {
+	Write(book domain.Book) error
+	Close() error
+}
+
+// NewWriter returns a Writer for the given export format.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	case FormatNDJSON:
+		return NewNDJSONWriter(w), nil
+	case FormatMARC:
+		return NewMARCWriter(w), nil
+	case FormatMARCXML:
+		return NewMARCXMLWriter(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// ContentType returns the media type of an export format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatMARC:
+		return "application/marc"
+	case FormatMARCXML:
+		return "application/marcxml+xml"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// CSVWriter writes the columns read by CSVReader, preceded by the book ID,
+// so that an export can be imported again.
+type CSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) header() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	return c.w.Write(append([]string{"id"}, csvColumns...))
+}
+
+func (c *CSVWriter) Write(book domain.Book) error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	publishedOn := ""
+	if book.PublishedOn != nil {
+		publishedOn = book.PublishedOn.String()
+	}
+
+	publisherID := ""
+	if book.PublisherID != nil {
+		publisherID = strconv.FormatInt(*book.PublisherID, 10)
+	}
+
+	return c.w.Write([]string{
+		strconv.Itoa(book.ID),
+		book.Name,
+		book.Author,
+		book.ISBN13,
+		publishedOn,
+		publisherID,
+		strconv.Itoa(book.Rating),
+	})
+}
+
+func (c *CSVWriter) Close() error {
+	if err := c.header(); err != nil {
+		return err
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// NDJSONWriter writes each book as a JSON object on its own line.
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *NDJSONWriter) Write(book domain.Book) error {
+	return n.enc.Encode(book)
+}
+
+func (n *NDJSONWriter) Close() error {
+	return nil
+}
+
+// MARCXMLWriter writes books as a MARCXML collection.
+type MARCXMLWriter struct {
+	w *marc.XMLWriter
+}
+
+func NewMARCXMLWriter(w io.Writer) *MARCXMLWriter {
+	return &MARCXMLWriter{w: marc.NewXMLWriter(w)}
+}
+
+func (m *MARCXMLWriter) Write(book domain.Book) error {
+	return m.w.Write(MARCRecord(book))
+}
+
+func (m *MARCXMLWriter) Close() error {
+	return m.w.Close()
+}
+
+// MARCWriter writes books as ISO 2709 MARC records.
+type MARCWriter struct {
+	w *marc.Writer
+}
+
+func NewMARCWriter(w io.Writer) *MARCWriter {
+	return &MARCWriter{w: marc.NewWriter(w)}
+}
+
+func (m *MARCWriter) Write(book domain.Book) error {
+	return m.w.Write(MARCRecord(book))
+}
+
+func (m *MARCWriter) Close() error {
+	return nil
+}
@@ -1,19 +1,110 @@
 package config
 
 import (
+	"lib/pkg/money"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
 	Server struct {
-		Port int `mapstructure:"port"`
+		Port            int           `mapstructure:"port"`
+		ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+		DrainDelay      time.Duration `mapstructure:"drain_delay"`
+		PublicURL       string        `mapstructure:"public_url"`
 	} `mapstructure:"server"`
 
 	Auth struct {
 		TokenTTL time.Duration `mapstructure:"token_ttl"`
 	} `mapstructure:"auth"`
+
+	Books struct {
+		TrashRetention time.Duration `mapstructure:"trash_retention"`
+
+		ImportBatchSize int `mapstructure:"import_batch_size"`
+	} `mapstructure:"books"`
+
+	Circulation struct {
+		MaxRenewals  int                      `mapstructure:"max_renewals"`
+		LoanPeriods  map[string]time.Duration `mapstructure:"loan_periods"`
+		PickupPeriod time.Duration            `mapstructure:"pickup_period"`
+	} `mapstructure:"circulation"`
+
+	Fines struct {
+		Rates      map[string]FineRate `mapstructure:"rates"`
+		MaxBalance money.Amount        `mapstructure:"max_balance"`
+	} `mapstructure:"fines"`
+
+	Scheduler struct {
+		Enabled bool                 `mapstructure:"enabled"`
+		Jobs    map[string]JobConfig `mapstructure:"jobs"`
+	} `mapstructure:"scheduler"`
+
+	Notifications struct {
+		WebhookURL string        `mapstructure:"webhook_url"`
+		Timeout    time.Duration `mapstructure:"timeout"`
+	} `mapstructure:"notifications"`
+
+	Covers struct {
+		Backend string `mapstructure:"backend"`
+		Dir     string `mapstructure:"dir"`
+
+		S3 struct {
+			Endpoint  string `mapstructure:"endpoint"`
+			Region    string `mapstructure:"region"`
+			Bucket    string `mapstructure:"bucket"`
+			PathStyle bool   `mapstructure:"path_style"`
+		} `mapstructure:"s3"`
+	} `mapstructure:"covers"`
+
+	Tracing struct {
+		ServiceName string  `mapstructure:"service_name"`
+		Exporter    string  `mapstructure:"exporter"`
+		Endpoint    string  `mapstructure:"endpoint"`
+		Insecure    bool    `mapstructure:"insecure"`
+		SampleRatio float64 `mapstructure:"sample_ratio"`
+	} `mapstructure:"tracing"`
+
+	Health struct {
+		DatabaseTimeout   time.Duration `mapstructure:"database_timeout"`
+		AuditTimeout      time.Duration `mapstructure:"audit_timeout"`
+		MigrationsTimeout time.Duration `mapstructure:"migrations_timeout"`
+	} `mapstructure:"health"`
+
+	Audit struct {
+		CheckpointInterval int `mapstructure:"checkpoint_interval"`
+
+		Policy struct {
+			Default AuditRule   `mapstructure:"default"`
+			Rules   []AuditRule `mapstructure:"rules"`
+		} `mapstructure:"policy"`
+	} `mapstructure:"audit"`
+}
+
+// AuditRule configures one entity/action pair. Unset fields fall back to
+// the policy default, and the default itself falls back to auditing every
+// event and failing the request when the event cannot be stored.
+type AuditRule struct {
+	Entity     string   `mapstructure:"entity"`
+	Action     string   `mapstructure:"action"`
+	Enabled    *bool    `mapstructure:"enabled"`
+	SampleRate *float64 `mapstructure:"sample_rate"`
+	Fatal      *bool    `mapstructure:"fatal"`
+}
+
+// FineRate is the daily fine and the per-loan cap of an item type.
+type FineRate struct {
+	PerDay money.Amount `mapstructure:"per_day"`
+	Cap    money.Amount `mapstructure:"cap"`
+}
+
+// JobConfig schedules a background job. Schedule is a cron expression.
+type JobConfig struct {
+	Schedule string        `mapstructure:"schedule"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+	Jitter   time.Duration `mapstructure:"jitter"`
 }
 
 func NewConfig(folder, filename string) (*Config, error) {
@@ -26,7 +117,14 @@ func NewConfig(folder, filename string) (*Config, error) {
 		return nil, err
 	}
 
-	if err := viper.Unmarshal(cfg); err != nil {
+	// Amounts are decoded through their text form, like durations.
+	hooks := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.TextUnmarshallerHookFunc(),
+	))
+
+	if err := viper.Unmarshal(cfg, hooks); err != nil {
 		return nil, err
 	}
 
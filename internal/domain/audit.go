@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// AuditRecord is a single entry of the local audit trail. Every record
+// carries the hash of its predecessor, so editing or removing any record
+// breaks the chain from that point on.
+type AuditRecord struct {
+	Seq       int64     `json:"seq"`
+	Entity    string    `json:"entity"`
+	Action    string    `json:"action"`
+	EntityID  int64     `json:"entity_id"`
+	ActorID   int64     `json:"actor_id"`
+	Timestamp time.Time `json:"timestamp"`
+	PrevHash  string    `json:"prev_hash"`
+	Hash      string    `json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 of the record contents including PrevHash.
+func (r AuditRecord) ComputeHash() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%s|%s|%d|%d|%s|%s",
+		r.Seq, r.Entity, r.Action, r.EntityID, r.ActorID,
+		r.Timestamp.UTC().Format(time.RFC3339Nano), r.PrevHash)))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditCheckpoint is a signed snapshot of the chain head at Seq.
+type AuditCheckpoint struct {
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	Signature string    `json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditVerification is the outcome of walking the audit chain.
+type AuditVerification struct {
+	Checked     int64  `json:"checked"`
+	Checkpoints int64  `json:"checkpoints"`
+	BrokenAt    int64  `json:"broken_at,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func (v AuditVerification) OK() bool {
+	return v.BrokenAt == 0
+}
+
+// AuditFilter selects audit records. Zero values are ignored; a zero Limit
+// returns every matching record.
+type AuditFilter struct {
+	Entity   string
+	Action   string
+	EntityID *int64
+	ActorID  *int64
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Roles an author can have on a book.
+const (
+	AuthorRoleAuthor     = "author"
+	AuthorRoleEditor     = "editor"
+	AuthorRoleTranslator = "translator"
+)
+
+type Author struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio"`
+	CreatedAt time.Time `json:"created_at"`
+	// Ratings is only filled when a single author is fetched.
+	Ratings *RatingStats `json:"ratings,omitempty"`
+}
+
+// RatingStats aggregates the ratings of a set of books outside the trash.
+// Average is taken over the Rated books, those with a non-zero rating. The
+// aggregates are recomputed periodically and may lag behind edits.
+type RatingStats struct {
+	Books   int     `json:"books"`
+	Rated   int     `json:"rated"`
+	Average float64 `json:"average"`
+}
+
+// BookAuthor links an author to a book in a role. Position orders the
+// contributors of a book.
+type BookAuthor struct {
+	AuthorID int64  `json:"author_id"`
+	Name     string `json:"name,omitempty"`
+	Role     string `json:"role"`
+	Position int    `json:"position"`
+}
+
+type AuthorInput struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Bio  string `json:"bio" validate:"max=10000"`
+}
+
+func (i *AuthorInput) Normalize() {
+	i.Name = normalizeText(i.Name)
+	i.Bio = normalizeText(i.Bio)
+}
+
+func (i AuthorInput) Validate() error {
+	return validationError(validate.Struct(i))
+}
+
+// BookAuthorInput references an existing author from a book payload.
+type BookAuthorInput struct {
+	AuthorID int64  `json:"author_id" validate:"required,gt=0"`
+	Role     string `json:"role" validate:"omitempty,oneof=author editor translator"`
+}
+
+// AuthorMergeInput folds one author into another.
+type AuthorMergeInput struct {
+	Into int64 `json:"into" validate:"required,gt=0"`
+}
+
+func (i AuthorMergeInput) Validate() error {
+	return validationError(validate.Struct(i))
+}
+
+// bookAuthors converts payload references into ordered book links.
+func bookAuthors(inp []BookAuthorInput) []BookAuthor {
+	if inp == nil {
+		return nil
+	}
+
+	authors := make([]BookAuthor, 0, len(inp))
+	for i, a := range inp {
+		role := a.Role
+		if role == "" {
+			role = AuthorRoleAuthor
+		}
+
+		authors = append(authors, BookAuthor{
+			AuthorID: a.AuthorID,
+			Role:     role,
+			Position: i,
+		})
+	}
+
+	return authors
+}
+
+// AuthorKey folds spelling variants of a name onto one key: case,
+// punctuation and word order are ignored, so "Tolstoy, Leo" and
+// "leo tolstoy" collide.
+func AuthorKey(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(normalizeText(name)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(words)
+
+	return strings.Join(words, " ")
+}
@@ -1,20 +1,254 @@
 package domain
 
 import (
+	"lib/pkg/isbn"
+	"strings"
 	"time"
 )
 
 type Book struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Author    string    `json:"author"`
-	Publisher time.Time `json:"publisher"`
-	Rating    int       `json:"rating"`
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Author string `json:"author"`
+	ISBN13 string `json:"isbn_13,omitempty"`
+	ISBN10 string `json:"isbn_10,omitempty"`
+	// Publisher is the first day of PublishedOn. It predates the publisher
+	// entity and is kept for older clients.
+	Publisher     *time.Time   `json:"publisher,omitempty"`
+	PublishedOn   *PartialDate `json:"published_on,omitempty"`
+	PublisherID   *int64       `json:"publisher_id,omitempty"`
+	PublisherName string       `json:"publisher_name,omitempty"`
+	Rating        int          `json:"rating"`
+	Version       int          `json:"version"`
+	DeletedAt     *time.Time   `json:"deleted_at,omitempty"`
+	Authors       []BookAuthor `json:"authors"`
+	// Availability is only filled in book listings.
+	Availability *Availability `json:"availability,omitempty"`
 }
 
+// SetPublishedOn sets the publication date together with its legacy
+// timestamp form.
+func (b *Book) SetPublishedOn(d *PartialDate) {
+	if d != nil && d.IsZero() {
+		d = nil
+	}
+
+	b.PublishedOn = d
+	b.Publisher = nil
+	if d != nil {
+		t := d.Time()
+		b.Publisher = &t
+	}
+}
+
+// SetISBN sets both ISBN forms from any valid ISBN. ISBN-13 outside the
+// 978 prefix have no ISBN-10.
+func (b *Book) SetISBN(s string) {
+	b.ISBN13, _ = isbn.To13(s)
+	b.ISBN10, _ = isbn.To10(s)
+}
+
+// UpdateBook is a partial update of a book. A nil Authors leaves the
+// contributors unchanged. A zero PublishedOn or PublisherID clears it.
 type UpdateBook struct {
-	Name      *string    `json:"name"`
-	Author    *string    `json:"author"`
-	Publisher *time.Time `json:"publisher"`
-	Rating    *int       `json:"rating"`
+	Name        *string      `json:"name"`
+	Author      *string      `json:"author"`
+	ISBN        *string      `json:"isbn"`
+	PublishedOn *PartialDate `json:"published_on"`
+	PublisherID *int64       `json:"publisher_id"`
+	Rating      *int         `json:"rating"`
+	Authors     []BookAuthor `json:"authors"`
+}
+
+// CreateBookInput is the API payload for creating a book. Contributors are
+// referenced by ID in Authors; the free-text Author is still accepted from
+// older clients and linked to an author of that name. ISBN may be given in
+// either form and is stored as ISBN-13. PublisherName links the publisher
+// of that name, created on demand, when PublisherID is absent. Likewise
+// the legacy Publisher timestamp is read as a publication day when
+// PublishedOn is absent.
+type CreateBookInput struct {
+	Name          string            `json:"name" validate:"required,max=255"`
+	Author        string            `json:"author" validate:"required_without=Authors,max=255"`
+	ISBN          string            `json:"isbn"`
+	Publisher     *time.Time        `json:"publisher" validate:"omitempty,notfuture"`
+	PublishedOn   *PartialDate      `json:"published_on"`
+	PublisherID   *int64            `json:"publisher_id" validate:"omitempty,gt=0"`
+	PublisherName string            `json:"publisher_name" validate:"max=255"`
+	Rating        *int              `json:"rating" validate:"omitempty,gte=0,lte=5"`
+	Authors       []BookAuthorInput `json:"authors" validate:"omitempty,dive"`
+}
+
+func (i *CreateBookInput) Normalize() {
+	i.Name = normalizeText(i.Name)
+	i.Author = normalizeText(i.Author)
+	i.ISBN = normalizeISBN(i.ISBN)
+	i.PublisherName = normalizeText(i.PublisherName)
+	i.PublishedOn = publishedOn(i.PublishedOn, i.Publisher)
+}
+
+func (i CreateBookInput) Validate() error {
+	if i.ISBN != "" {
+		if err := validateISBN(i.ISBN); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePublishedOn(i.PublishedOn); err != nil {
+		return err
+	}
+
+	return validationError(validate.Struct(i))
+}
+
+func (i CreateBookInput) Book() Book {
+	book := Book{
+		Name:          i.Name,
+		Author:        i.Author,
+		PublisherID:   i.PublisherID,
+		PublisherName: i.PublisherName,
+		Authors:       bookAuthors(i.Authors),
+	}
+	if i.Rating != nil {
+		book.Rating = *i.Rating
+	}
+	book.SetISBN(i.ISBN)
+	book.SetPublishedOn(publishedOn(i.PublishedOn, i.Publisher))
+
+	return book
+}
+
+// UpdateBookInput is the API payload for a partial book update. Nil fields
+// are left unchanged. An empty isbn or published_on and a zero
+// publisher_id clear the field.
+type UpdateBookInput struct {
+	Name        *string           `json:"name" validate:"omitempty,min=1,max=255"`
+	Author      *string           `json:"author" validate:"omitempty,min=1,max=255"`
+	ISBN        *string           `json:"isbn"`
+	Publisher   *time.Time        `json:"publisher" validate:"omitempty,notfuture"`
+	PublishedOn *PartialDate      `json:"published_on"`
+	PublisherID *int64            `json:"publisher_id" validate:"omitempty,gte=0"`
+	Rating      *int              `json:"rating" validate:"omitempty,gte=0,lte=5"`
+	Authors     []BookAuthorInput `json:"authors" validate:"omitempty,dive"`
+}
+
+func (i *UpdateBookInput) Normalize() {
+	if i.Name != nil {
+		name := normalizeText(*i.Name)
+		i.Name = &name
+	}
+
+	if i.Author != nil {
+		author := normalizeText(*i.Author)
+		i.Author = &author
+	}
+
+	if i.ISBN != nil {
+		isbn := normalizeISBN(*i.ISBN)
+		i.ISBN = &isbn
+	}
+
+	i.PublishedOn = publishedOn(i.PublishedOn, i.Publisher)
+}
+
+func (i UpdateBookInput) Validate() error {
+	if i.Name == nil && i.Author == nil && i.ISBN == nil && i.Publisher == nil && i.PublishedOn == nil &&
+		i.PublisherID == nil && i.Rating == nil && i.Authors == nil {
+		return NewValidationError(FieldError{Field: "body", Message: "at least one field is required"})
+	}
+
+	if i.Authors != nil && len(i.Authors) == 0 {
+		return NewValidationError(FieldError{Field: "authors", Message: "must not be empty"})
+	}
+
+	if i.ISBN != nil && *i.ISBN != "" {
+		if err := validateISBN(*i.ISBN); err != nil {
+			return err
+		}
+	}
+
+	if err := validatePublishedOn(i.PublishedOn); err != nil {
+		return err
+	}
+
+	return validationError(validate.Struct(i))
+}
+
+func (i UpdateBookInput) UpdateBook() UpdateBook {
+	return UpdateBook{
+		Name:        i.Name,
+		Author:      i.Author,
+		ISBN:        i.ISBN,
+		PublishedOn: publishedOn(i.PublishedOn, i.Publisher),
+		PublisherID: i.PublisherID,
+		Rating:      i.Rating,
+		Authors:     bookAuthors(i.Authors),
+	}
+}
+
+// publishedOn prefers the explicit publication date and falls back to the
+// legacy publisher timestamp at day precision.
+func publishedOn(d *PartialDate, legacy *time.Time) *PartialDate {
+	if d != nil || legacy == nil {
+		return d
+	}
+
+	converted := NewPartialDate(legacy.UTC(), PrecisionDay)
+	return &converted
+}
+
+func validatePublishedOn(d *PartialDate) error {
+	if d != nil && d.Time().After(time.Now()) {
+		return NewValidationError(FieldError{Field: "published_on", Message: "must not be in the future"})
+	}
+	return nil
+}
+
+// normalizeISBN converts a valid ISBN to its ISBN-13 form and leaves
+// anything else for validateISBN to reject.
+func normalizeISBN(s string) string {
+	if isbn13, err := isbn.To13(s); err == nil {
+		return isbn13
+	}
+	return isbn.Clean(s)
+}
+
+func validateISBN(s string) error {
+	_, err := ParseISBN(s)
+	return err
+}
+
+// ParseISBN returns the ISBN-13 form of an ISBN given in either form, or a
+// ValidationError.
+func ParseISBN(s string) (string, error) {
+	isbn13, err := isbn.To13(s)
+	if err != nil {
+		return "", NewValidationError(FieldError{Field: "isbn", Message: strings.TrimPrefix(err.Error(), "isbn: ")})
+	}
+	return isbn13, nil
+}
+
+// Book listing orders.
+const (
+	BookSortID     = "id"
+	BookSortNewest = "newest"
+	BookSortRating = "rating"
+)
+
+// BookFilter selects books. Zero values are ignored; a zero Limit returns
+// every matching book. Query, Name and Author match case-insensitive
+// substrings, Query in either the name or the author. PublishedFrom and
+// PublishedTo are inclusive years. Sort is one of the BookSort orders and
+// defaults to BookSortID.
+type BookFilter struct {
+	Query         string
+	Name          string
+	Author        string
+	PublisherID   *int64
+	MinRating     *int64
+	PublishedFrom *int64
+	PublishedTo   *int64
+	Sort          string
+	Limit         int
+	Offset        int
 }
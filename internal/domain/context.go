@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+type ctxKey int
+
+const (
+	ctxUserID ctxKey = iota
+)
+
+// WithUserID returns a copy of ctx carrying the authenticated user ID.
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, ctxUserID, id)
+}
+
+// UserIDFromContext returns the authenticated user ID stored in ctx.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(ctxUserID).(int64)
+	return id, ok
+}
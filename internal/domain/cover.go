@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Cover sizes. The original is kept as uploaded; the others are thumbnails
+// no larger than CoverSizes[size] pixels on either side.
+const (
+	CoverOriginal = "original"
+	CoverSmall    = "small"
+	CoverMedium   = "medium"
+	CoverLarge    = "large"
+)
+
+var CoverSizes = map[string]int{
+	CoverSmall:  96,
+	CoverMedium: 240,
+	CoverLarge:  480,
+}
+
+// Cover describes the cover image of a book. Checksum is the hex SHA-256
+// of the original.
+type Cover struct {
+	BookID      int64     `json:"book_id"`
+	ContentType string    `json:"content_type"`
+	Width       int       `json:"width"`
+	Height      int       `json:"height"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// MediaType returns the media type of the cover at size. Thumbnails of
+// JPEG covers are JPEG; all other thumbnails are PNG.
+func (c Cover) MediaType(size string) string {
+	if size == CoverOriginal || c.ContentType == "image/jpeg" {
+		return c.ContentType
+	}
+	return "image/png"
+}
+
+// ParseCoverSize validates a requested cover size. The empty string selects
+// the original.
+func ParseCoverSize(s string) (string, error) {
+	if s == "" || s == CoverOriginal {
+		return CoverOriginal, nil
+	}
+
+	if _, ok := CoverSizes[s]; !ok {
+		return "", NewValidationError(FieldError{Field: "size", Message: "must be one of: original small medium large"})
+	}
+
+	return s, nil
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Precisions of a PartialDate.
+const (
+	PrecisionYear  = "year"
+	PrecisionMonth = "month"
+	PrecisionDay   = "day"
+)
+
+// PartialDate is a calendar date known to year, month or day precision.
+// It is written in JSON as "2006", "2006-01" or "2006-01-02". The zero
+// PartialDate is no date at all and is read from an empty JSON string.
+type PartialDate struct {
+	Year  int
+	Month int
+	Day   int
+}
+
+func NewPartialDate(t time.Time, precision string) PartialDate {
+	d := PartialDate{Year: t.Year()}
+	if precision == PrecisionMonth || precision == PrecisionDay {
+		d.Month = int(t.Month())
+	}
+	if precision == PrecisionDay {
+		d.Day = t.Day()
+	}
+	return d
+}
+
+func ParsePartialDate(s string) (PartialDate, error) {
+	for _, layout := range []struct {
+		layout    string
+		precision string
+	}{
+		{"2006-01-02", PrecisionDay},
+		{"2006-01", PrecisionMonth},
+		{"2006", PrecisionYear},
+	} {
+		if t, err := time.Parse(layout.layout, s); err == nil {
+			return NewPartialDate(t, layout.precision), nil
+		}
+	}
+
+	return PartialDate{}, fmt.Errorf("invalid date %q: expected YYYY, YYYY-MM or YYYY-MM-DD", s)
+}
+
+// IsZero reports whether d is the zero PartialDate.
+func (d PartialDate) IsZero() bool {
+	return d == PartialDate{}
+}
+
+func (d PartialDate) Precision() string {
+	switch {
+	case d.Day != 0:
+		return PrecisionDay
+	case d.Month != 0:
+		return PrecisionMonth
+	default:
+		return PrecisionYear
+	}
+}
+
+// Time returns the first instant covered by the date, in UTC.
+func (d PartialDate) Time() time.Time {
+	month, day := d.Month, d.Day
+	if month == 0 {
+		month = 1
+	}
+	if day == 0 {
+		day = 1
+	}
+
+	return time.Date(d.Year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+func (d PartialDate) String() string {
+	switch d.Precision() {
+	case PrecisionDay:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	case PrecisionMonth:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	default:
+		return fmt.Sprintf("%04d", d.Year)
+	}
+}
+
+func (d PartialDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *PartialDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	if s == "" {
+		*d = PartialDate{}
+		return nil
+	}
+
+	parsed, err := ParsePartialDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+	return nil
+}
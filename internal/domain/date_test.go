@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParsePartialDate(t *testing.T) {
+	tests := []struct {
+		input     string
+		want      PartialDate
+		precision string
+		ok        bool
+	}{
+		{"1965", PartialDate{Year: 1965}, PrecisionYear, true},
+		{"1965-08", PartialDate{Year: 1965, Month: 8}, PrecisionMonth, true},
+		{"1965-08-01", PartialDate{Year: 1965, Month: 8, Day: 1}, PrecisionDay, true},
+		{"2024-02-29", PartialDate{Year: 2024, Month: 2, Day: 29}, PrecisionDay, true},
+		{"0999", PartialDate{Year: 999}, PrecisionYear, true},
+		{"", PartialDate{}, "", false},
+		{"65", PartialDate{}, "", false},
+		{"1965-8", PartialDate{}, "", false},
+		{"1965-13", PartialDate{}, "", false},
+		{"1965-00", PartialDate{}, "", false},
+		{"2023-02-29", PartialDate{}, "", false},
+		{"1965-08-01T00:00:00Z", PartialDate{}, "", false},
+		{"1965/08/01", PartialDate{}, "", false},
+		{" 1965", PartialDate{}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePartialDate(tt.input)
+			if (err == nil) != tt.ok {
+				t.Fatalf("ParsePartialDate(%q) error = %v, want ok %v", tt.input, err, tt.ok)
+			}
+			if !tt.ok {
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("ParsePartialDate(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if p := got.Precision(); p != tt.precision {
+				t.Errorf("Precision() = %q, want %q", p, tt.precision)
+			}
+			if s := got.String(); s != tt.input {
+				t.Errorf("String() = %q, want %q", s, tt.input)
+			}
+		})
+	}
+}
+
+func TestPartialDateTime(t *testing.T) {
+	tests := []struct {
+		date PartialDate
+		want time.Time
+	}{
+		{PartialDate{Year: 1965}, time.Date(1965, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{PartialDate{Year: 1965, Month: 8}, time.Date(1965, time.August, 1, 0, 0, 0, 0, time.UTC)},
+		{PartialDate{Year: 1965, Month: 8, Day: 17}, time.Date(1965, time.August, 17, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.date.Time(); !got.Equal(tt.want) {
+			t.Errorf("%s.Time() = %v, want %v", tt.date, got, tt.want)
+		}
+
+		if got := NewPartialDate(tt.date.Time(), tt.date.Precision()); got != tt.date {
+			t.Errorf("NewPartialDate(%s) = %+v, want %+v", tt.date, got, tt.date)
+		}
+	}
+}
+
+func TestPartialDateJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  PartialDate
+		ok    bool
+	}{
+		{`"1965"`, PartialDate{Year: 1965}, true},
+		{`"1965-08"`, PartialDate{Year: 1965, Month: 8}, true},
+		{`"1965-08-01"`, PartialDate{Year: 1965, Month: 8, Day: 1}, true},
+		{`""`, PartialDate{}, true},
+		{`1965`, PartialDate{}, false},
+		{`"August 1965"`, PartialDate{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var got PartialDate
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if (err == nil) != tt.ok {
+				t.Fatalf("Unmarshal(%s) error = %v, want ok %v", tt.input, err, tt.ok)
+			}
+			if !tt.ok {
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if got.IsZero() {
+				return
+			}
+
+			out, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.input {
+				t.Errorf("Marshal(%+v) = %s, want %s", got, out, tt.input)
+			}
+		})
+	}
+}
+
+func TestUpdateInputClearsMissingFields(t *testing.T) {
+	rev := BookRevision{Snapshot: Book{Name: "Dune", Author: "Frank Herbert"}}
+
+	inp := rev.UpdateInput()
+	if inp.PublishedOn == nil || !inp.PublishedOn.IsZero() {
+		t.Errorf("PublishedOn = %v, want the zero date", inp.PublishedOn)
+	}
+	if inp.PublisherID == nil || *inp.PublisherID != 0 {
+		t.Errorf("PublisherID = %v, want 0", inp.PublisherID)
+	}
+	if inp.ISBN == nil || *inp.ISBN != "" {
+		t.Errorf("ISBN = %v, want empty", inp.ISBN)
+	}
+	if err := inp.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
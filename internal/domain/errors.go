@@ -1,9 +1,148 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-playground/validator"
+)
+
+// Error kinds. Every error returned by the services either is or wraps one
+// of these, and the transport layer maps the kind onto a response status.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrTooLarge     = errors.New("too large")
+	ErrUnsupported  = errors.New("unsupported media type")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
 
 var (
-	ErrBookNotFound        = errors.New("book not found")
-	ErrUserNotFound        = errors.New("user not found")
-	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrBookNotFound          = newError(ErrNotFound, "book not found")
+	ErrUserNotFound          = newError(ErrNotFound, "user not found")
+	ErrRevisionNotFound      = newError(ErrNotFound, "revision not found")
+	ErrAuthorNotFound        = newError(ErrNotFound, "author not found")
+	ErrPublisherNotFound     = newError(ErrNotFound, "publisher not found")
+	ErrImportNotFound        = newError(ErrNotFound, "import job not found")
+	ErrCoverNotFound         = newError(ErrNotFound, "cover not found")
+	ErrItemNotFound          = newError(ErrNotFound, "item not found")
+	ErrLoanNotFound          = newError(ErrNotFound, "loan not found")
+	ErrHoldNotFound          = newError(ErrNotFound, "hold not found")
+	ErrInvalidCredentials    = newError(ErrUnauthorized, "invalid email or password")
+	ErrRefreshTokenNotFound  = newError(ErrUnauthorized, "refresh token not found")
+	ErrRefreshTokenExpired   = newError(ErrUnauthorized, "refresh token expired")
+	ErrUserAlreadyRegistered = newError(ErrConflict, "user already registered")
+	ErrISBNTaken             = newError(ErrConflict, "isbn is already assigned to another book")
+	ErrBarcodeTaken          = newError(ErrConflict, "barcode is already assigned to another item")
+	ErrItemOnLoan            = newError(ErrConflict, "item is on loan")
+	ErrItemOnHold            = newError(ErrConflict, "item is held for another reader")
+	ErrItemNotAvailable      = newError(ErrConflict, "item is not available for loan")
+	ErrItemNotLendable       = newError(ErrConflict, "items of this type are not lent")
+	ErrLoanReturned          = newError(ErrConflict, "loan is already returned")
+	ErrRenewalLimit          = newError(ErrConflict, "loan has reached the renewal limit")
+	ErrRenewalHeld           = newError(ErrConflict, "loan cannot be renewed while other readers wait for the book")
+	ErrHoldExists            = newError(ErrConflict, "book is already on hold for this user")
+	ErrHoldClosed            = newError(ErrConflict, "hold is no longer active")
+	ErrBalanceLimit          = newError(ErrConflict, "user owes more than the checkout limit")
+	ErrCreditExceedsBalance  = newError(ErrConflict, "amount exceeds the outstanding balance")
+	ErrBookVersionMismatch   = newError(ErrPreconditionFailed, "book was modified by another request")
+	ErrCoverType             = newError(ErrUnsupported, "cover must be a JPEG, PNG or GIF image")
 )
+
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func newError(kind error, msg string) error {
+	return &kindError{kind: kind, msg: msg}
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError carries per-field details and matches ErrValidation.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func NewValidationError(fields ...FieldError) *ValidationError {
+	return &ValidationError{Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// validationError turns the result of validate.Struct into a ValidationError.
+func validationError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	fields := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, FieldError{
+			Field:   fe.Field(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return NewValidationError(fields...)
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "gte", "min":
+		return "must be at least " + fe.Param()
+	case "lte", "max":
+		return "must be at most " + fe.Param()
+	case "required_without":
+		return "is required when " + fe.Param() + " is empty"
+	case "oneof":
+		return "must be one of: " + fe.Param()
+	case "gt":
+		return "must be greater than " + fe.Param()
+	case "len":
+		return "must have length " + fe.Param()
+	case "alpha":
+		return "must contain letters only"
+	case "url":
+		return "must be a valid URL"
+	case "notfuture":
+		return "must not be in the future"
+	default:
+		return "failed on " + fe.Tag()
+	}
+}
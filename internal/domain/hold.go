@@ -0,0 +1,64 @@
+package domain
+
+import "time"
+
+// Hold statuses. A hold waits in its title's queue until a copy is
+// allocated to it, then stays ready until the copy is checked out, the
+// pickup period runs out or the hold is cancelled.
+const (
+	HoldWaiting   = "waiting"
+	HoldReady     = "ready"
+	HoldFulfilled = "fulfilled"
+	HoldExpired   = "expired"
+	HoldCancelled = "cancelled"
+)
+
+// Hold is a reader's place in the queue for a title. Position is the
+// 1-based place of a waiting hold in its queue, and ItemID and Barcode
+// name the copy set aside for a ready hold.
+type Hold struct {
+	ID        int64      `json:"id"`
+	BookID    int64      `json:"book_id"`
+	UserID    int64      `json:"user_id"`
+	Title     string     `json:"title"`
+	Status    string     `json:"status"`
+	Position  int        `json:"position,omitempty"`
+	ItemID    *int64     `json:"item_id,omitempty"`
+	Barcode   string     `json:"barcode,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// HoldInput is the API payload for placing a hold on a title.
+type HoldInput struct {
+	BookID int64 `json:"book_id" validate:"required,gt=0"`
+}
+
+func (i HoldInput) Validate() error {
+	return validationError(validate.Struct(i))
+}
+
+// HoldFilter selects holds. Zero values are ignored; Active selects
+// waiting and ready holds.
+type HoldFilter struct {
+	UserID *int64
+	BookID *int64
+	Active bool
+}
+
+// Notification kinds.
+const (
+	NotificationHoldReady   = "hold_ready"
+	NotificationHoldExpired = "hold_expired"
+)
+
+// Notification is a message to a user about their holds.
+type Notification struct {
+	Kind    string `json:"kind"`
+	UserID  int64  `json:"user_id"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HoldID  int64  `json:"hold_id,omitempty"`
+}
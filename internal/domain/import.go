@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Import formats.
+const (
+	ImportFormatCSV     = "csv"
+	ImportFormatNDJSON  = "ndjson"
+	ImportFormatMARC    = "marc"
+	ImportFormatMARCXML = "marcxml"
+)
+
+// Import job statuses.
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportSucceeded = "succeeded"
+	ImportFailed    = "failed"
+)
+
+// ImportJob tracks one bulk import. Report is filled in once the job
+// finishes; Error is set when it failed as a whole.
+type ImportJob struct {
+	ID         int64         `json:"id"`
+	Status     string        `json:"status"`
+	Format     string        `json:"format"`
+	DryRun     bool          `json:"dry_run"`
+	ActorID    int64         `json:"actor_id"`
+	Report     *ImportReport `json:"report,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+}
+
+// maxImportErrors caps ImportReport.Errors.
+const maxImportErrors = 1000
+
+// ImportReport summarizes an import. Errors lists rejected rows, capped so
+// that a badly broken file does not produce an unbounded report.
+type ImportReport struct {
+	Total           int              `json:"total"`
+	Created         int              `json:"created"`
+	Updated         int              `json:"updated"`
+	Failed          int              `json:"failed"`
+	Errors          []ImportRowError `json:"errors"`
+	ErrorsTruncated bool             `json:"errors_truncated,omitempty"`
+}
+
+// Reject counts row as failed and records why.
+func (r *ImportReport) Reject(row int, err error) {
+	r.Failed++
+	if len(r.Errors) >= maxImportErrors {
+		r.ErrorsTruncated = true
+		return
+	}
+
+	rowErr := ImportRowError{Row: row, Message: err.Error()}
+
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		rowErr.Message = ErrValidation.Error()
+		rowErr.Fields = verr.Fields
+	}
+
+	r.Errors = append(r.Errors, rowErr)
+}
+
+// ImportRowError explains why a row was rejected. Row counts data rows
+// from 1, not counting a CSV header.
+type ImportRowError struct {
+	Row     int          `json:"row"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+// ImportResult is the outcome of writing one imported book.
+type ImportResult struct {
+	Created bool
+	Err     error
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"lib/pkg/barcode"
+	"strings"
+	"time"
+)
+
+// Item statuses.
+const (
+	ItemAvailable = "available"
+	ItemOnLoan    = "on_loan"
+	ItemOnHold    = "on_hold"
+	ItemLost      = "lost"
+	ItemInRepair  = "in_repair"
+)
+
+// Item types. Loan periods are configured per type; types without a loan
+// period, such as reference works, cannot be lent.
+const (
+	ItemTypeBook       = "book"
+	ItemTypeReference  = "reference"
+	ItemTypePeriodical = "periodical"
+	ItemTypeMedia      = "media"
+)
+
+// Item conditions, best first.
+const (
+	ConditionNew     = "new"
+	ConditionGood    = "good"
+	ConditionFair    = "fair"
+	ConditionPoor    = "poor"
+	ConditionDamaged = "damaged"
+)
+
+// Item is a physical copy of a book. Symbology is derived from Barcode.
+type Item struct {
+	ID         int64        `json:"id"`
+	BookID     int64        `json:"book_id"`
+	Barcode    string       `json:"barcode"`
+	Symbology  string       `json:"symbology"`
+	Type       string       `json:"type"`
+	Shelf      string       `json:"shelf"`
+	Condition  string       `json:"condition"`
+	AcquiredOn *PartialDate `json:"acquired_on,omitempty"`
+	Status     string       `json:"status"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// SetBarcode sets the barcode together with its symbology.
+func (i *Item) SetBarcode(s string) {
+	i.Barcode = s
+	i.Symbology, _ = barcode.Parse(s)
+}
+
+// ItemInput is the API payload for creating or replacing an item. Type
+// defaults to book, Condition to good and Status to available. Items are
+// put on loan or on hold by circulation, never directly, and keep that
+// status until circulation releases them.
+type ItemInput struct {
+	BookID     int64        `json:"book_id" validate:"required,gt=0"`
+	Barcode    string       `json:"barcode" validate:"required"`
+	Type       string       `json:"type" validate:"oneof=book reference periodical media"`
+	Shelf      string       `json:"shelf" validate:"max=64"`
+	Condition  string       `json:"condition" validate:"oneof=new good fair poor damaged"`
+	AcquiredOn *PartialDate `json:"acquired_on"`
+	Status     string       `json:"status" validate:"oneof=available lost in_repair"`
+}
+
+func (i *ItemInput) Normalize() {
+	i.Barcode = strings.TrimSpace(i.Barcode)
+	i.Shelf = normalizeText(i.Shelf)
+
+	if i.Type == "" {
+		i.Type = ItemTypeBook
+	}
+	if i.Condition == "" {
+		i.Condition = ConditionGood
+	}
+	if i.Status == "" {
+		i.Status = ItemAvailable
+	}
+}
+
+func (i ItemInput) Validate() error {
+	if i.Barcode != "" {
+		if _, err := ParseBarcode(i.Barcode); err != nil {
+			return err
+		}
+	}
+
+	if d := i.AcquiredOn; d != nil {
+		if d.Precision() != PrecisionDay {
+			return NewValidationError(FieldError{Field: "acquired_on", Message: "must be a full date"})
+		}
+		if d.Time().After(time.Now()) {
+			return NewValidationError(FieldError{Field: "acquired_on", Message: "must not be in the future"})
+		}
+	}
+
+	return validationError(validate.Struct(i))
+}
+
+func (i ItemInput) Item() Item {
+	item := Item{
+		BookID:     i.BookID,
+		Type:       i.Type,
+		Shelf:      i.Shelf,
+		Condition:  i.Condition,
+		AcquiredOn: i.AcquiredOn,
+		Status:     i.Status,
+	}
+	item.SetBarcode(i.Barcode)
+
+	return item
+}
+
+// ParseBarcode validates an item barcode as EAN-13 or Code 128 and returns
+// its symbology, or a ValidationError.
+func ParseBarcode(s string) (string, error) {
+	symbology, err := barcode.Parse(s)
+	if err != nil {
+		return "", NewValidationError(FieldError{Field: "barcode", Message: strings.TrimPrefix(err.Error(), "barcode: ")})
+	}
+	return symbology, nil
+}
+
+// ItemFilter selects items. Zero values are ignored.
+type ItemFilter struct {
+	BookID *int64
+	Status string
+}
+
+// Availability counts the copies of a book.
+type Availability struct {
+	Total     int `json:"total"`
+	Available int `json:"available"`
+}
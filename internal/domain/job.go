@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// JobRun is one run of a scheduled job. Result is empty while the run is
+// in progress.
+type JobRun struct {
+	ID         int64      `json:"id"`
+	Job        string     `json:"job"`
+	Instance   string     `json:"instance"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Result     string     `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// JobRunFilter selects job runs, most recent first. An empty Job selects
+// every job; a zero Limit returns every run.
+type JobRunFilter struct {
+	Job   string
+	Limit int
+}
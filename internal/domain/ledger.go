@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"lib/pkg/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Ledger entry kinds. Fines are charges; payments and waivers are credits
+// recorded by librarians.
+const (
+	LedgerFine    = "fine"
+	LedgerPayment = "payment"
+	LedgerWaiver  = "waiver"
+)
+
+// LedgerEntry is one line of a reader's account. Amount is positive for
+// charges and negative for credits. Fines name the loan and the overdue
+// day they charge for.
+type LedgerEntry struct {
+	ID         int64        `json:"id"`
+	UserID     int64        `json:"user_id"`
+	Kind       string       `json:"kind"`
+	Amount     money.Amount `json:"amount"`
+	LoanID     *int64       `json:"loan_id,omitempty"`
+	AccruedOn  *PartialDate `json:"accrued_on,omitempty"`
+	Note       string       `json:"note,omitempty"`
+	RecordedBy *int64       `json:"recorded_by,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+}
+
+// Account is a reader's balance, the sum of their ledger entries, with the
+// entries themselves, most recent first.
+type Account struct {
+	UserID  int64         `json:"user_id"`
+	Balance money.Amount  `json:"balance"`
+	Entries []LedgerEntry `json:"entries"`
+}
+
+// FineRate is the fine per overdue day for an item type and the most a
+// single loan can be charged. A zero Cap leaves fines uncapped.
+type FineRate struct {
+	PerDay money.Amount
+	Cap    money.Amount
+}
+
+// FinePolicy holds the fine rates of every item type. Types without a
+// rate are not fined.
+type FinePolicy struct {
+	Rates map[string]FineRate
+}
+
+// FinedTypes returns the item types with a positive daily fine, in order.
+func (p FinePolicy) FinedTypes() []string {
+	types := make([]string, 0, len(p.Rates))
+	for itemType, rate := range p.Rates {
+		if rate.PerDay > 0 {
+			types = append(types, itemType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CreditInput is the API payload for recording a payment or a waiver. A
+// waiver must give its reason in Note.
+type CreditInput struct {
+	Amount money.Amount `json:"amount" validate:"gt=0,lte=100000000"`
+	Note   string       `json:"note" validate:"max=255"`
+}
+
+func (i *CreditInput) Normalize() {
+	i.Note = strings.TrimSpace(i.Note)
+}
+
+// Validate checks the input for a credit of the given kind.
+func (i CreditInput) Validate(kind string) error {
+	if kind == LedgerWaiver && i.Note == "" {
+		return NewValidationError(FieldError{Field: "note", Message: "is required for waivers"})
+	}
+
+	return validationError(validate.Struct(i))
+}
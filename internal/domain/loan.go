@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"lib/pkg/money"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Loan is the lending of an item to a user. BookID, Title and Barcode
+// describe the item for display.
+type Loan struct {
+	ID           int64      `json:"id"`
+	ItemID       int64      `json:"item_id"`
+	UserID       int64      `json:"user_id"`
+	BookID       int64      `json:"book_id"`
+	Title        string     `json:"title"`
+	Barcode      string     `json:"barcode"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	Renewals     int        `json:"renewals"`
+	Overdue      bool       `json:"overdue"`
+}
+
+// IsOverdue reports whether the loan is still open past its due date.
+func (l Loan) IsOverdue(now time.Time) bool {
+	return l.ReturnedAt == nil && now.After(l.DueAt)
+}
+
+// LoanPolicy holds the circulation rules: the loan period of every
+// lendable item type, how often a loan may be renewed, how long a copy
+// allocated to a hold waits for pickup and the balance above which a
+// reader cannot borrow. Fines are the rates that count toward the balance
+// before they are accrued.
+type LoanPolicy struct {
+	Periods      map[string]time.Duration
+	MaxRenewals  int
+	PickupPeriod time.Duration
+	MaxBalance   money.Amount
+	Fines        FinePolicy
+}
+
+// Period returns the loan period of itemType, or ErrItemNotLendable.
+func (p LoanPolicy) Period(itemType string) (time.Duration, error) {
+	period := p.Periods[itemType]
+	if period <= 0 {
+		return 0, ErrItemNotLendable
+	}
+	return period, nil
+}
+
+// LendableTypes returns the item types that have a loan period.
+func (p LoanPolicy) LendableTypes() []string {
+	types := make([]string, 0, len(p.Periods))
+	for itemType, period := range p.Periods {
+		if period > 0 {
+			types = append(types, itemType)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// CheckoutInput is the API payload for lending an item, identified by ID
+// or by barcode, to a user.
+type CheckoutInput struct {
+	ItemID  int64  `json:"item_id" validate:"required_without=Barcode,omitempty,gt=0"`
+	Barcode string `json:"barcode"`
+	UserID  int64  `json:"user_id" validate:"required,gt=0"`
+}
+
+func (i *CheckoutInput) Normalize() {
+	i.Barcode = strings.TrimSpace(i.Barcode)
+}
+
+func (i CheckoutInput) Validate() error {
+	if i.Barcode != "" {
+		if _, err := ParseBarcode(i.Barcode); err != nil {
+			return err
+		}
+	}
+
+	return validationError(validate.Struct(i))
+}
+
+// LoanFilter selects loans. Zero values are ignored. Open selects loans not
+// yet returned; Overdue selects open loans past their due date.
+type LoanFilter struct {
+	UserID  *int64
+	ItemID  *int64
+	Open    bool
+	Overdue bool
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type Publisher struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Country   string    `json:"country"`
+	Website   string    `json:"website"`
+	CreatedAt time.Time `json:"created_at"`
+	// Ratings is only filled when a single publisher is fetched.
+	Ratings *RatingStats `json:"ratings,omitempty"`
+}
+
+// PublisherInput is the API payload for creating or replacing a publisher.
+// Country is an ISO 3166-1 alpha-2 code.
+type PublisherInput struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Country string `json:"country" validate:"omitempty,len=2,alpha"`
+	Website string `json:"website" validate:"omitempty,url,max=255"`
+}
+
+func (i *PublisherInput) Normalize() {
+	i.Name = normalizeText(i.Name)
+	i.Country = strings.ToUpper(strings.TrimSpace(i.Country))
+	i.Website = strings.TrimSpace(i.Website)
+}
+
+func (i PublisherInput) Validate() error {
+	return validationError(validate.Struct(i))
+}
+
+func (i PublisherInput) Publisher() Publisher {
+	return Publisher{
+		Name:    i.Name,
+		Country: i.Country,
+		Website: i.Website,
+	}
+}
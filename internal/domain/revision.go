@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+)
+
+// Revision actions.
+const (
+	RevisionCreate  = "CREATE"
+	RevisionUpdate  = "UPDATE"
+	RevisionDelete  = "DELETE"
+	RevisionRestore = "RESTORE"
+)
+
+// BookRevision is the state of a book right after a change, together with
+// what changed. Version equals the book version the change produced.
+type BookRevision struct {
+	ID        int64                  `json:"id"`
+	BookID    int64                  `json:"book_id"`
+	Version   int                    `json:"version"`
+	Action    string                 `json:"action"`
+	Snapshot  Book                   `json:"snapshot"`
+	Diff      map[string]FieldChange `json:"diff"`
+	ActorID   int64                  `json:"actor_id"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// DiffBooks returns the user-visible fields that differ between old and new.
+func DiffBooks(old, new Book) map[string]FieldChange {
+	diff := make(map[string]FieldChange)
+
+	add := func(field string, o, n interface{}) {
+		if !reflect.DeepEqual(o, n) {
+			diff[field] = FieldChange{Old: o, New: n}
+		}
+	}
+
+	add("name", old.Name, new.Name)
+	add("author", old.Author, new.Author)
+	add("isbn", old.ISBN13, new.ISBN13)
+	add("published_on", dateOrNil(old.PublishedOn), dateOrNil(new.PublishedOn))
+	add("publisher_id", idOrNil(old.PublisherID), idOrNil(new.PublisherID))
+	add("rating", old.Rating, new.Rating)
+	add("deleted_at", utcOrNil(old.DeletedAt), utcOrNil(new.DeletedAt))
+	add("authors", authorRefs(old.Authors), authorRefs(new.Authors))
+
+	return diff
+}
+
+// authorRefs strips display names so that renaming an author does not show
+// up as a change to the book.
+func authorRefs(authors []BookAuthor) []BookAuthor {
+	refs := make([]BookAuthor, 0, len(authors))
+	for _, a := range authors {
+		refs = append(refs, BookAuthor{AuthorID: a.AuthorID, Role: a.Role, Position: a.Position})
+	}
+	return refs
+}
+
+func utcOrNil(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func dateOrNil(d *PartialDate) interface{} {
+	if d == nil {
+		return nil
+	}
+	return d.String()
+}
+
+func idOrNil(id *int64) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+// UpdateInput returns the update that brings a book back to this revision.
+// Fields the revision lacks are cleared.
+func (r BookRevision) UpdateInput() UpdateBookInput {
+	inp := UpdateBookInput{
+		Name:        &r.Snapshot.Name,
+		Author:      &r.Snapshot.Author,
+		PublishedOn: publishedOn(r.Snapshot.PublishedOn, r.Snapshot.Publisher),
+		PublisherID: r.Snapshot.PublisherID,
+		Rating:      &r.Snapshot.Rating,
+	}
+
+	if inp.PublishedOn == nil {
+		inp.PublishedOn = &PartialDate{}
+	}
+
+	if inp.PublisherID == nil {
+		inp.PublisherID = new(int64)
+	}
+
+	isbn := r.Snapshot.ISBN13
+	inp.ISBN = &isbn
+
+	if len(r.Snapshot.Authors) > 0 {
+		inp.Authors = make([]BookAuthorInput, 0, len(r.Snapshot.Authors))
+		for _, a := range r.Snapshot.Authors {
+			inp.Authors = append(inp.Authors, BookAuthorInput{AuthorID: a.AuthorID, Role: a.Role})
+		}
+	}
+
+	return inp
+}
@@ -2,24 +2,26 @@ package domain
 
 import (
 	"time"
-
-	"github.com/go-playground/validator"
 )
 
-var validate *validator.Validate
-
-func init() {
-	validate = validator.New()
-}
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
 
 type User struct {
 	ID           int64     `json:"id"`
 	Name         string    `json:"name"`
 	Email        string    `json:"email"`
 	Password     string    `json:"password"`
+	Role         string    `json:"role"`
 	RegisteredAt time.Time `json:"registered_at"`
 }
 
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
 type SignUpInput struct {
 	Name     string `json:"name" validate:"required,gte=2"`
 	Email    string `json:"email" validate:"required,email"`
@@ -27,7 +29,7 @@ type SignUpInput struct {
 }
 
 func (i SignUpInput) Validate() error {
-	return validate.Struct(i)
+	return validationError(validate.Struct(i))
 }
 
 type SignInInput struct {
@@ -36,5 +38,5 @@ type SignInInput struct {
 }
 
 func (i SignInInput) Validate() error {
-	return validate.Struct(i)
+	return validationError(validate.Struct(i))
 }
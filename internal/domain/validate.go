@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator"
+	"golang.org/x/text/unicode/norm"
+)
+
+var validate *validator.Validate
+
+func init() {
+	validate = validator.New()
+
+	// Report JSON field names in validation errors.
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	validate.RegisterValidation("notfuture", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return !ok || !t.After(time.Now())
+	})
+}
+
+// normalizeText converts s to Unicode NFC, drops control characters and
+// collapses runs of whitespace into single spaces.
+func normalizeText(s string) string {
+	s = norm.NFC.String(s)
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+)
+
+type auditSender interface {
+	SendLogRequest(ctx context.Context, req audit.LogItem) error
+}
+
+// AuditClient measures latency and failures of the wrapped audit client.
+type AuditClient struct {
+	next    auditSender
+	metrics *Metrics
+}
+
+func NewAuditClient(next auditSender, m *Metrics) *AuditClient {
+	return &AuditClient{
+		next:    next,
+		metrics: m,
+	}
+}
+
+func (c *AuditClient) SendLogRequest(ctx context.Context, req audit.LogItem) error {
+	start := time.Now()
+	err := c.next.SendLogRequest(ctx, req)
+	c.metrics.ObserveAuditSend(req.Entity, req.Action, time.Since(start), err)
+
+	return err
+}
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"database/sql"
+	"lib/pkg/scheduler"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "lib"
+
+// Metrics owns every collector exposed by the service. It registers them on
+// the registry it is given instead of the global default, so each instance
+// (and each test) gets an isolated set.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	auditDuration *prometheus.HistogramVec
+	auditFailures *prometheus.CounterVec
+
+	signIns        *prometheus.CounterVec
+	tokenRefreshes *prometheus.CounterVec
+
+	jobRuns     *prometheus.CounterVec
+	jobDuration *prometheus.HistogramVec
+	jobLeader   prometheus.Gauge
+}
+
+func New(registry *prometheus.Registry) *Metrics {
+	m := &Metrics{
+		registry: registry,
+
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method and route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+
+		auditDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "audit",
+			Name:      "send_duration_seconds",
+			Help:      "Latency of sending audit events.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"entity", "action"}),
+		auditFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "audit",
+			Name:      "send_failures_total",
+			Help:      "Audit events that could not be sent.",
+		}, []string{"entity", "action"}),
+
+		signIns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "sign_ins_total",
+			Help:      "Sign-in attempts by result.",
+		}, []string{"result"}),
+		tokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "token_refreshes_total",
+			Help:      "Token refresh attempts by result.",
+		}, []string{"result"}),
+
+		jobRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "runs_total",
+			Help:      "Scheduled job runs by job and result.",
+		}, []string{"job", "result"}),
+		jobDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the scheduled job runs this instance made.",
+			Buckets:   []float64{0.01, 0.1, 1, 10, 60, 300, 1800},
+		}, []string{"job"}),
+		jobLeader: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "scheduler",
+			Name:      "leader",
+			Help:      "Whether this instance runs the scheduled jobs.",
+		}),
+	}
+
+	registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.auditDuration,
+		m.auditFailures,
+		m.signIns,
+		m.tokenRefreshes,
+		m.jobRuns,
+		m.jobDuration,
+		m.jobLeader,
+	)
+
+	return m
+}
+
+// RegisterDB exposes sql.DB.Stats() of the pool as gauges.
+func (m *Metrics) RegisterDB(name string, db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRequest(method, route string, status int, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+func (m *Metrics) ObserveAuditSend(entity, action string, d time.Duration, err error) {
+	m.auditDuration.WithLabelValues(entity, action).Observe(d.Seconds())
+	if err != nil {
+		m.auditFailures.WithLabelValues(entity, action).Inc()
+	}
+}
+
+// Result labels for auth counters.
+const (
+	ResultSuccess = "success"
+	ResultDenied  = "denied"
+	ResultError   = "error"
+)
+
+func (m *Metrics) SignIn(result string) {
+	m.signIns.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) TokenRefresh(result string) {
+	m.tokenRefreshes.WithLabelValues(result).Inc()
+}
+
+// ObserveJob counts a scheduled job run. Runs skipped by followers have no
+// duration.
+func (m *Metrics) ObserveJob(job, result string, d time.Duration) {
+	m.jobRuns.WithLabelValues(job, result).Inc()
+	if result != scheduler.ResultSkipped {
+		m.jobDuration.WithLabelValues(job).Observe(d.Seconds())
+	}
+}
+
+func (m *Metrics) SetLeader(leader bool) {
+	if leader {
+		m.jobLeader.Set(1)
+	} else {
+		m.jobLeader.Set(0)
+	}
+}
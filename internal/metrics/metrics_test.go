@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"lib/pkg/scheduler"
+	"testing"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeAuditSender struct {
+	err error
+}
+
+func (s fakeAuditSender) SendLogRequest(context.Context, audit.LogItem) error {
+	return s.err
+}
+
+func TestAuditClientCountsFailures(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+	item := audit.LogItem{Entity: audit.ENTITY_BOOK, Action: audit.ACTION_CREATE}
+
+	if err := NewAuditClient(fakeAuditSender{}, m).SendLogRequest(context.Background(), item); err != nil {
+		t.Fatal(err)
+	}
+
+	errSend := errors.New("audit unavailable")
+	if err := NewAuditClient(fakeAuditSender{err: errSend}, m).SendLogRequest(context.Background(), item); !errors.Is(err, errSend) {
+		t.Fatalf("SendLogRequest = %v, want %v", err, errSend)
+	}
+
+	if n := testutil.CollectAndCount(m.auditDuration); n != 1 {
+		t.Errorf("got %d audit duration series, want 1", n)
+	}
+	if got := testutil.ToFloat64(m.auditFailures.WithLabelValues(item.Entity, item.Action)); got != 1 {
+		t.Errorf("audit failures = %v, want 1", got)
+	}
+}
+
+func TestObserveJob(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.ObserveJob("purge_trash", scheduler.ResultSuccess, time.Second)
+	m.ObserveJob("purge_trash", scheduler.ResultSkipped, 0)
+	m.ObserveJob("expire_holds", scheduler.ResultSkipped, 0)
+
+	tests := []struct {
+		job, result string
+		want        float64
+	}{
+		{"purge_trash", scheduler.ResultSuccess, 1},
+		{"purge_trash", scheduler.ResultSkipped, 1},
+		{"expire_holds", scheduler.ResultSkipped, 1},
+		{"expire_holds", scheduler.ResultSuccess, 0},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(m.jobRuns.WithLabelValues(tt.job, tt.result)); got != tt.want {
+			t.Errorf("runs of %s with %s = %v, want %v", tt.job, tt.result, got, tt.want)
+		}
+	}
+
+	// Skipped runs have no duration.
+	if n := testutil.CollectAndCount(m.jobDuration); n != 1 {
+		t.Errorf("got %d job duration series, want 1", n)
+	}
+}
+
+func TestSetLeader(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.SetLeader(true)
+	if got := testutil.ToFloat64(m.jobLeader); got != 1 {
+		t.Errorf("leader = %v after SetLeader(true), want 1", got)
+	}
+
+	m.SetLeader(false)
+	if got := testutil.ToFloat64(m.jobLeader); got != 0 {
+		t.Errorf("leader = %v after SetLeader(false), want 0", got)
+	}
+}
+
+func TestInstancesAreIsolated(t *testing.T) {
+	a, b := New(prometheus.NewRegistry()), New(prometheus.NewRegistry())
+
+	a.SignIn(ResultDenied)
+	a.ObserveRequest("GET", "/books", 200, time.Millisecond)
+
+	if got := testutil.ToFloat64(b.signIns.WithLabelValues(ResultDenied)); got != 0 {
+		t.Errorf("sign-ins seen by another instance = %v, want 0", got)
+	}
+	if n := testutil.CollectAndCount(b.httpRequests); n != 0 {
+		t.Errorf("got %d request series on another instance, want 0", n)
+	}
+	if got := testutil.ToFloat64(a.httpRequests.WithLabelValues("GET", "/books", "200")); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+}
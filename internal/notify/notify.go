@@ -0,0 +1,66 @@
+// Package notify delivers notifications to readers.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"lib/internal/domain"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Log writes notifications to the application log. It stands in for a
+// real channel in development and when none is configured.
+type Log struct{}
+
+func (Log) Notify(ctx context.Context, n domain.Notification) error {
+	log.WithFields(log.Fields{
+		"kind":    n.Kind,
+		"user_id": n.UserID,
+		"hold_id": n.HoldID,
+	}).Info(n.Subject)
+	return nil
+}
+
+// Webhook posts every notification as JSON to a URL, leaving delivery to
+// the receiving service.
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (w *Webhook) Notify(ctx context.Context, n domain.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify: webhook responded %s", resp.Status)
+	}
+
+	return nil
+}
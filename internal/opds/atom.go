@@ -0,0 +1,191 @@
+package opds
+
+import (
+	"encoding/xml"
+	"lib/internal/domain"
+	"time"
+)
+
+// Feed is an OPDS 1.2 catalog feed.
+type Feed struct {
+	XMLName   xml.Name `xml:"feed"`
+	Namespace string   `xml:"xmlns,attr"`
+	NSDC      string   `xml:"xmlns:dc,attr"`
+	NSSearch  string   `xml:"xmlns:opensearch,attr,omitempty"`
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Author    Person   `xml:"author"`
+	// Search result counts, in feeds answering a search.
+	ItemsPerPage int     `xml:"opensearch:itemsPerPage,omitempty"`
+	StartIndex   int     `xml:"opensearch:startIndex,omitempty"`
+	Links        []Link  `xml:"link"`
+	Entries      []Entry `xml:"entry"`
+}
+
+type Person struct {
+	Name string `xml:"name"`
+}
+
+type Link struct {
+	Rel   string `xml:"rel,attr,omitempty"`
+	Href  string `xml:"href,attr"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type Entry struct {
+	Title        string   `xml:"title"`
+	ID           string   `xml:"id"`
+	Updated      string   `xml:"updated"`
+	Authors      []Person `xml:"author"`
+	Contributors []Person `xml:"contributor"`
+	Issued       string   `xml:"dc:issued,omitempty"`
+	Publisher    string   `xml:"dc:publisher,omitempty"`
+	Identifiers  []string `xml:"dc:identifier"`
+	Content      *Content `xml:"content,omitempty"`
+	Links        []Link   `xml:"link"`
+}
+
+type Content struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+const (
+	namespaceAtom       = "http://www.w3.org/2005/Atom"
+	namespaceDC         = "http://purl.org/dc/terms/"
+	namespaceOpenSearch = "http://a9.com/-/spec/opensearch/1.1/"
+)
+
+// catalogAuthor is the author of every feed.
+var catalogAuthor = Person{Name: "lib"}
+
+func newFeed(base, path, title string, updated time.Time) Feed {
+	return Feed{
+		Namespace: namespaceAtom,
+		NSDC:      namespaceDC,
+		ID:        base + path,
+		Title:     title,
+		Updated:   updated.UTC().Format(time.RFC3339),
+		Author:    catalogAuthor,
+		Links: []Link{
+			{Rel: "self", Href: base + path, Type: TypeNavigation},
+			{Rel: "start", Href: base + Root, Type: TypeNavigation},
+			{Rel: "search", Href: base + OpenSearchPath, Type: TypeOpenSearch},
+		},
+	}
+}
+
+// Navigation returns the root navigation feed linking every section.
+func Navigation(base string, updated time.Time) Feed {
+	feed := newFeed(base, Root, "Library catalog", updated)
+
+	for _, s := range Sections {
+		feed.Entries = append(feed.Entries, Entry{
+			Title:   s.Title,
+			ID:      base + s.Path,
+			Updated: feed.Updated,
+			Content: &Content{Type: "text", Text: s.Summary},
+			Links:   []Link{{Rel: s.Rel, Href: base + s.Path, Type: TypeAcquisition}},
+		})
+		feed.Links = append(feed.Links, Link{Rel: s.Rel, Href: base + s.Path, Type: TypeAcquisition, Title: s.Title})
+	}
+
+	return feed
+}
+
+// Acquisition returns one page of an acquisition feed, with links to the
+// neighbouring pages.
+func Acquisition(base string, p Page) Feed {
+	self := p.pageURL(base, p.Number)
+
+	feed := newFeed(base, p.Path, p.Title, p.Updated)
+	feed.ID = self
+	feed.Links[0] = Link{Rel: "self", Href: self, Type: TypeAcquisition}
+	feed.Links = append(feed.Links, Link{Rel: "up", Href: base + Root, Type: TypeNavigation})
+	feed.Links = append(feed.Links, Link{Rel: "first", Href: p.pageURL(base, 1), Type: TypeAcquisition})
+
+	if p.Number > 1 {
+		feed.Links = append(feed.Links, Link{Rel: "previous", Href: p.pageURL(base, p.Number-1), Type: TypeAcquisition})
+	}
+	if p.HasNext {
+		feed.Links = append(feed.Links, Link{Rel: "next", Href: p.pageURL(base, p.Number+1), Type: TypeAcquisition})
+	}
+
+	if p.Path == SearchPath {
+		feed.NSSearch = namespaceOpenSearch
+		feed.ItemsPerPage = p.Size
+		feed.StartIndex = (p.Number-1)*p.Size + 1
+	}
+
+	for _, book := range p.Books {
+		feed.Entries = append(feed.Entries, bookEntry(base, book, feed.Updated))
+	}
+
+	return feed
+}
+
+func bookEntry(base string, book domain.Book, updated string) Entry {
+	entry := Entry{
+		Title:     book.Name,
+		ID:        bookURN(book),
+		Updated:   updated,
+		Publisher: book.PublisherName,
+		Content:   &Content{Type: "text", Text: ratingSummary(book)},
+		Links: []Link{
+			{Rel: RelBorrow, Href: bookURL(base, book), Type: TypeBook},
+			{Rel: "alternate", Href: bookURL(base, book), Type: TypeBook},
+		},
+	}
+
+	names := contributors(book)
+	for _, name := range names[domain.AuthorRoleAuthor] {
+		entry.Authors = append(entry.Authors, Person{Name: name})
+	}
+	for _, role := range []string{domain.AuthorRoleEditor, domain.AuthorRoleTranslator} {
+		for _, name := range names[role] {
+			entry.Contributors = append(entry.Contributors, Person{Name: name})
+		}
+	}
+
+	if book.PublishedOn != nil {
+		entry.Issued = book.PublishedOn.String()
+	}
+
+	if book.ISBN13 != "" {
+		entry.Identifiers = append(entry.Identifiers, "urn:isbn:"+book.ISBN13)
+	}
+
+	return entry
+}
+
+// OpenSearchDescription describes catalog search for OPDS clients.
+type OpenSearchDescription struct {
+	XMLName     xml.Name        `xml:"OpenSearchDescription"`
+	Namespace   string          `xml:"xmlns,attr"`
+	ShortName   string          `xml:"ShortName"`
+	Description string          `xml:"Description"`
+	InputEnc    string          `xml:"InputEncoding"`
+	OutputEnc   string          `xml:"OutputEncoding"`
+	URLs        []OpenSearchURL `xml:"Url"`
+}
+
+type OpenSearchURL struct {
+	Type     string `xml:"type,attr"`
+	Template string `xml:"template,attr"`
+}
+
+func OpenSearch(base string) OpenSearchDescription {
+	return OpenSearchDescription{
+		Namespace:   namespaceOpenSearch,
+		ShortName:   "lib",
+		Description: "Search the library catalog by title or author.",
+		InputEnc:    "UTF-8",
+		OutputEnc:   "UTF-8",
+		URLs: []OpenSearchURL{
+			{Type: TypeAcquisition, Template: base + SearchPath + "?q={searchTerms}&page={startPage?}"},
+			{Type: TypeJSON, Template: base + SearchPath + "?q={searchTerms}&page={startPage?}"},
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package opds
+
+import "lib/internal/domain"
+
+// JSONFeed is an OPDS 2.0 catalog feed. Publications is only nil in
+// navigation feeds; acquisition feeds list it even when empty.
+type JSONFeed struct {
+	Metadata     JSONMetadata   `json:"metadata"`
+	Links        []JSONLink     `json:"links"`
+	Navigation   []JSONLink     `json:"navigation,omitempty"`
+	Publications *[]Publication `json:"publications,omitempty"`
+}
+
+type JSONMetadata struct {
+	Title        string `json:"title"`
+	ItemsPerPage int    `json:"itemsPerPage,omitempty"`
+	CurrentPage  int    `json:"currentPage,omitempty"`
+}
+
+type JSONLink struct {
+	Rel       string `json:"rel,omitempty"`
+	Href      string `json:"href"`
+	Type      string `json:"type,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Templated bool   `json:"templated,omitempty"`
+}
+
+type Publication struct {
+	Metadata PublicationMetadata `json:"metadata"`
+	Links    []JSONLink          `json:"links"`
+}
+
+type PublicationMetadata struct {
+	Type        string        `json:"@type"`
+	Identifier  string        `json:"identifier"`
+	Title       string        `json:"title"`
+	Author      []Contributor `json:"author,omitempty"`
+	Editor      []Contributor `json:"editor,omitempty"`
+	Translator  []Contributor `json:"translator,omitempty"`
+	Publisher   string        `json:"publisher,omitempty"`
+	Published   string        `json:"published,omitempty"`
+	Description string        `json:"description,omitempty"`
+}
+
+type Contributor struct {
+	Name string `json:"name"`
+}
+
+func newJSONFeed(base, self, title string) JSONFeed {
+	return JSONFeed{
+		Metadata: JSONMetadata{Title: title},
+		Links: []JSONLink{
+			{Rel: "self", Href: self, Type: TypeJSON},
+			{Rel: "start", Href: base + Root, Type: TypeJSON},
+			{Rel: "search", Href: base + SearchPath + "{?q,page}", Type: TypeJSON, Templated: true},
+		},
+	}
+}
+
+// NavigationJSON returns the root navigation feed linking every section.
+func NavigationJSON(base string) JSONFeed {
+	feed := newJSONFeed(base, base+Root, "Library catalog")
+
+	for _, s := range Sections {
+		feed.Navigation = append(feed.Navigation, JSONLink{Rel: s.Rel, Href: base + s.Path, Type: TypeJSON, Title: s.Title})
+	}
+
+	return feed
+}
+
+// AcquisitionJSON returns one page of an acquisition feed, with links to
+// the neighbouring pages.
+func AcquisitionJSON(base string, p Page) JSONFeed {
+	feed := newJSONFeed(base, p.pageURL(base, p.Number), p.Title)
+	feed.Metadata.ItemsPerPage = p.Size
+	feed.Metadata.CurrentPage = p.Number
+
+	feed.Links = append(feed.Links, JSONLink{Rel: "up", Href: base + Root, Type: TypeJSON})
+	feed.Links = append(feed.Links, JSONLink{Rel: "first", Href: p.pageURL(base, 1), Type: TypeJSON})
+
+	if p.Number > 1 {
+		feed.Links = append(feed.Links, JSONLink{Rel: "previous", Href: p.pageURL(base, p.Number-1), Type: TypeJSON})
+	}
+	if p.HasNext {
+		feed.Links = append(feed.Links, JSONLink{Rel: "next", Href: p.pageURL(base, p.Number+1), Type: TypeJSON})
+	}
+
+	// An empty page still lists its publications, so clients can tell it
+	// from a navigation feed.
+	publications := make([]Publication, 0, len(p.Books))
+	for _, book := range p.Books {
+		publications = append(publications, publication(base, book))
+	}
+	feed.Publications = &publications
+
+	return feed
+}
+
+func publication(base string, book domain.Book) Publication {
+	meta := PublicationMetadata{
+		Type:        "http://schema.org/Book",
+		Identifier:  bookURN(book),
+		Title:       book.Name,
+		Publisher:   book.PublisherName,
+		Description: ratingSummary(book),
+	}
+
+	if book.ISBN13 != "" {
+		meta.Identifier = "urn:isbn:" + book.ISBN13
+	}
+
+	if book.PublishedOn != nil {
+		meta.Published = book.PublishedOn.String()
+	}
+
+	names := contributors(book)
+	meta.Author = contributorList(names[domain.AuthorRoleAuthor])
+	meta.Editor = contributorList(names[domain.AuthorRoleEditor])
+	meta.Translator = contributorList(names[domain.AuthorRoleTranslator])
+
+	return Publication{
+		Metadata: meta,
+		Links: []JSONLink{
+			{Rel: "self", Href: bookURL(base, book), Type: TypeBook},
+			{Rel: RelBorrow, Href: bookURL(base, book), Type: TypeBook},
+		},
+	}
+}
+
+func contributorList(names []string) []Contributor {
+	var list []Contributor
+	for _, name := range names {
+		list = append(list, Contributor{Name: name})
+	}
+	return list
+}
@@ -0,0 +1,137 @@
+// Package opds builds OPDS catalog feeds of books: OPDS 1.2 Atom feeds and
+// OPDS 2.0 JSON feeds, with an OpenSearch description for catalog search.
+package opds
+
+import (
+	"lib/internal/domain"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Media types.
+const (
+	TypeNavigation  = "application/atom+xml;profile=opds-catalog;kind=navigation"
+	TypeAcquisition = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	TypeJSON        = "application/opds+json"
+	TypeOpenSearch  = "application/opensearchdescription+xml"
+	TypeBook        = "application/json"
+)
+
+// Link relations.
+const (
+	RelBorrow     = "http://opds-spec.org/acquisition/borrow"
+	RelSortNew    = "http://opds-spec.org/sort/new"
+	RelFeatured   = "http://opds-spec.org/featured"
+	RelSubsection = "subsection"
+)
+
+// Root is the path of the catalog root. Every other path is below it.
+const Root = "/opds"
+
+// HighRating is the rating from which a book counts as highly rated.
+const HighRating = 4
+
+// Section is an acquisition feed listed in the root navigation feed.
+type Section struct {
+	Path    string
+	Title   string
+	Summary string
+	Rel     string
+	Filter  domain.BookFilter
+}
+
+var highRating int64 = HighRating
+
+// Sections are the acquisition feeds of the catalog. All of them are built
+// from the book rating and catalogue order; none reflects how often books
+// are borrowed, so there is no "popular" feed.
+var Sections = []Section{
+	{
+		Path:    Root + "/new",
+		Title:   "New books",
+		Summary: "Most recently catalogued books.",
+		Rel:     RelSortNew,
+		Filter:  domain.BookFilter{Sort: domain.BookSortNewest},
+	},
+	{
+		Path:    Root + "/highly-rated",
+		Title:   "Highly rated books",
+		Summary: "Most recently catalogued books rated " + strconv.Itoa(HighRating) + " or more.",
+		Rel:     RelSubsection,
+		Filter:  domain.BookFilter{Sort: domain.BookSortNewest, MinRating: &highRating},
+	},
+	{
+		Path:    Root + "/top-rated",
+		Title:   "Top rated books",
+		Summary: "Books by rating, best first.",
+		Rel:     RelFeatured,
+		Filter:  domain.BookFilter{Sort: domain.BookSortRating},
+	},
+}
+
+// SearchPath is the path of search results; the terms go in q.
+const SearchPath = Root + "/search"
+
+// OpenSearchPath is the path of the OpenSearch description.
+const OpenSearchPath = Root + "/opensearch.xml"
+
+// Page is one page of an acquisition feed. Page numbers start at 1.
+type Page struct {
+	Path    string
+	Title   string
+	Query   url.Values
+	Books   []domain.Book
+	Number  int
+	Size    int
+	HasNext bool
+	Updated time.Time
+}
+
+// pageURL returns the absolute URL of page n of p.
+func (p Page) pageURL(base string, n int) string {
+	q := url.Values{}
+	for k, v := range p.Query {
+		q[k] = v
+	}
+	if n > 1 {
+		q.Set("page", strconv.Itoa(n))
+	}
+
+	return absolute(base, p.Path, q)
+}
+
+func absolute(base, path string, q url.Values) string {
+	u := base + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
+
+func bookURL(base string, book domain.Book) string {
+	return base + "/books/" + strconv.Itoa(book.ID)
+}
+
+func bookURN(book domain.Book) string {
+	return "urn:lib:book:" + strconv.Itoa(book.ID)
+}
+
+// contributors splits the contributors of a book by role, falling back to
+// the display author of books without linked authors.
+func contributors(book domain.Book) map[string][]string {
+	names := make(map[string][]string)
+	for _, a := range book.Authors {
+		names[a.Role] = append(names[a.Role], a.Name)
+	}
+
+	if len(book.Authors) == 0 && book.Author != "" {
+		names[domain.AuthorRoleAuthor] = []string{book.Author}
+	}
+
+	return names
+}
+
+func ratingSummary(book domain.Book) string {
+	return "Rated " + strconv.Itoa(book.Rating) + " of 5."
+}
@@ -0,0 +1,295 @@
+package opds
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"lib/internal/domain"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBase = "https://lib.example"
+
+var testUpdated = time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func testBook() domain.Book {
+	published := domain.PartialDate{Year: 1965, Month: 8}
+	return domain.Book{
+		ID:            7,
+		Name:          "Dune",
+		Author:        "Frank Herbert",
+		ISBN13:        "9780441172719",
+		PublishedOn:   &published,
+		PublisherName: "Chilton",
+		Rating:        5,
+		Authors: []domain.BookAuthor{
+			{AuthorID: 1, Name: "Frank Herbert", Role: domain.AuthorRoleAuthor},
+			{AuthorID: 2, Name: "John Schoenherr", Role: domain.AuthorRoleEditor},
+		},
+	}
+}
+
+// links indexes feed links by relation.
+func links(feed Feed) map[string]string {
+	m := make(map[string]string, len(feed.Links))
+	for _, l := range feed.Links {
+		m[l.Rel] = l.Href
+	}
+	return m
+}
+
+func TestAcquisitionLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		page Page
+		want map[string]string
+	}{
+		{
+			name: "first page",
+			page: Page{Path: Root + "/new", Number: 1, Size: 25, HasNext: true},
+			want: map[string]string{
+				"self":     testBase + "/opds/new",
+				"first":    testBase + "/opds/new",
+				"next":     testBase + "/opds/new?page=2",
+				"previous": "",
+			},
+		},
+		{
+			name: "middle page",
+			page: Page{Path: Root + "/new", Number: 3, Size: 25, HasNext: true},
+			want: map[string]string{
+				"self":     testBase + "/opds/new?page=3",
+				"previous": testBase + "/opds/new?page=2",
+				"next":     testBase + "/opds/new?page=4",
+			},
+		},
+		{
+			name: "last page",
+			page: Page{Path: Root + "/new", Number: 2, Size: 25},
+			want: map[string]string{
+				"self":     testBase + "/opds/new?page=2",
+				"previous": testBase + "/opds/new",
+				"next":     "",
+			},
+		},
+		{
+			name: "search keeps terms",
+			page: Page{Path: SearchPath, Query: url.Values{"q": {"dune & co"}}, Number: 2, Size: 25, HasNext: true},
+			want: map[string]string{
+				"self":     testBase + "/opds/search?page=2&q=dune+%26+co",
+				"previous": testBase + "/opds/search?q=dune+%26+co",
+				"next":     testBase + "/opds/search?page=3&q=dune+%26+co",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := links(Acquisition(testBase, tt.page))
+			for rel, href := range tt.want {
+				if got[rel] != href {
+					t.Errorf("%s link = %q, want %q", rel, got[rel], href)
+				}
+			}
+
+			jsonFeed := AcquisitionJSON(testBase, tt.page)
+			jsonLinks := make(map[string]string)
+			for _, l := range jsonFeed.Links {
+				jsonLinks[l.Rel] = l.Href
+			}
+			for rel, href := range tt.want {
+				if jsonLinks[rel] != href {
+					t.Errorf("JSON %s link = %q, want %q", rel, jsonLinks[rel], href)
+				}
+			}
+		})
+	}
+}
+
+func TestAcquisitionAtom(t *testing.T) {
+	page := Page{
+		Path:    SearchPath,
+		Title:   "Search results",
+		Query:   url.Values{"q": {"dune"}},
+		Books:   []domain.Book{testBook()},
+		Number:  3,
+		Size:    25,
+		Updated: testUpdated,
+	}
+
+	body, err := xml.Marshal(Acquisition(testBase, page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feed struct {
+		ID           string `xml:"id"`
+		Updated      string `xml:"updated"`
+		ItemsPerPage int    `xml:"itemsPerPage"`
+		StartIndex   int    `xml:"startIndex"`
+		Entries      []struct {
+			Title        string   `xml:"title"`
+			ID           string   `xml:"id"`
+			Authors      []string `xml:"author>name"`
+			Contributors []string `xml:"contributor>name"`
+			Issued       string   `xml:"issued"`
+			Publisher    string   `xml:"publisher"`
+			Identifiers  []string `xml:"identifier"`
+			Content      string   `xml:"content"`
+			Links        []Link   `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatalf("unmarshal %s: %v", body, err)
+	}
+
+	for _, ns := range []string{namespaceAtom, namespaceDC, namespaceOpenSearch} {
+		if !strings.Contains(string(body), `"`+ns+`"`) {
+			t.Errorf("feed does not declare namespace %s", ns)
+		}
+	}
+
+	if feed.ID != testBase+"/opds/search?page=3&q=dune" {
+		t.Errorf("id = %q", feed.ID)
+	}
+	if feed.Updated != "2026-03-01T12:00:00Z" {
+		t.Errorf("updated = %q", feed.Updated)
+	}
+	if feed.ItemsPerPage != 25 || feed.StartIndex != 51 {
+		t.Errorf("itemsPerPage, startIndex = %d, %d, want 25, 51", feed.ItemsPerPage, feed.StartIndex)
+	}
+
+	if len(feed.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(feed.Entries))
+	}
+
+	entry := feed.Entries[0]
+	switch {
+	case entry.Title != "Dune":
+		t.Errorf("title = %q", entry.Title)
+	case entry.ID != "urn:lib:book:7":
+		t.Errorf("id = %q", entry.ID)
+	case strings.Join(entry.Authors, ",") != "Frank Herbert":
+		t.Errorf("authors = %v", entry.Authors)
+	case strings.Join(entry.Contributors, ",") != "John Schoenherr":
+		t.Errorf("contributors = %v", entry.Contributors)
+	case entry.Issued != "1965-08":
+		t.Errorf("issued = %q", entry.Issued)
+	case entry.Publisher != "Chilton":
+		t.Errorf("publisher = %q", entry.Publisher)
+	case strings.Join(entry.Identifiers, ",") != "urn:isbn:9780441172719":
+		t.Errorf("identifiers = %v", entry.Identifiers)
+	case entry.Content != "Rated 5 of 5.":
+		t.Errorf("content = %q", entry.Content)
+	}
+
+	var borrow string
+	for _, l := range entry.Links {
+		if l.Rel == RelBorrow {
+			borrow = l.Href
+		}
+	}
+	if borrow != testBase+"/books/7" {
+		t.Errorf("borrow link = %q", borrow)
+	}
+}
+
+func TestAcquisitionAtomOmitsSearchCounts(t *testing.T) {
+	body, err := xml.Marshal(Acquisition(testBase, Page{Path: Root + "/new", Number: 1, Size: 25}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{"xmlns:opensearch", "itemsPerPage", "startIndex", "<entry>"} {
+		if strings.Contains(string(body), s) {
+			t.Errorf("feed contains %q: %s", s, body)
+		}
+	}
+}
+
+func TestAcquisitionJSON(t *testing.T) {
+	page := Page{Path: Root + "/new", Title: "New books", Books: []domain.Book{testBook()}, Number: 1, Size: 25}
+
+	body, err := json.Marshal(AcquisitionJSON(testBase, page))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feed struct {
+		Metadata struct {
+			Title        string `json:"title"`
+			ItemsPerPage int    `json:"itemsPerPage"`
+			CurrentPage  int    `json:"currentPage"`
+		} `json:"metadata"`
+		Publications []struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"publications"`
+	}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Metadata.Title != "New books" || feed.Metadata.ItemsPerPage != 25 || feed.Metadata.CurrentPage != 1 {
+		t.Errorf("metadata = %+v", feed.Metadata)
+	}
+
+	if len(feed.Publications) != 1 {
+		t.Fatalf("got %d publications, want 1", len(feed.Publications))
+	}
+
+	meta := feed.Publications[0].Metadata
+	want := map[string]interface{}{
+		"@type":      "http://schema.org/Book",
+		"identifier": "urn:isbn:9780441172719",
+		"title":      "Dune",
+		"publisher":  "Chilton",
+		"published":  "1965-08",
+	}
+	for k, v := range want {
+		if meta[k] != v {
+			t.Errorf("%s = %v, want %v", k, meta[k], v)
+		}
+	}
+	if _, ok := meta["translator"]; ok {
+		t.Errorf("translator present without translators: %v", meta["translator"])
+	}
+}
+
+func TestAcquisitionJSONEmptyPage(t *testing.T) {
+	body, err := json.Marshal(AcquisitionJSON(testBase, Page{Path: SearchPath, Number: 1, Size: 25}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(body), `"publications":[]`) {
+		t.Errorf("empty page does not list publications: %s", body)
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	feed := Navigation(testBase, testUpdated)
+	if len(feed.Entries) != len(Sections) {
+		t.Fatalf("got %d entries, want %d", len(feed.Entries), len(Sections))
+	}
+
+	for i, s := range Sections {
+		entry := feed.Entries[i]
+		if entry.Title != s.Title || entry.ID != testBase+s.Path {
+			t.Errorf("entry %d = %q %q, want %q %q", i, entry.Title, entry.ID, s.Title, testBase+s.Path)
+		}
+		if len(entry.Links) != 1 || entry.Links[0].Type != TypeAcquisition {
+			t.Errorf("entry %d links = %+v", i, entry.Links)
+		}
+	}
+
+	if _, err := xml.Marshal(feed); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonFeed := NavigationJSON(testBase)
+	if len(jsonFeed.Navigation) != len(Sections) {
+		t.Errorf("got %d JSON navigation links, want %d", len(jsonFeed.Navigation), len(Sections))
+	}
+}
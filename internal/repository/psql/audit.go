@@ -0,0 +1,178 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+	"strings"
+)
+
+// auditLockKey serializes appends to the audit chain across connections.
+const auditLockKey = 0x61756469
+
+type Audit struct {
+	db *sql.DB
+}
+
+func NewAudit(db *sql.DB) *Audit {
+	return &Audit{
+		db: db,
+	}
+}
+
+// Append links rec to the current chain head and stores it. Seq, PrevHash
+// and Hash are assigned here, under a transaction-scoped advisory lock.
+func (a *Audit) Append(ctx context.Context, rec domain.AuditRecord) (domain.AuditRecord, error) {
+	tx, err := a.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.AuditRecord{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", auditLockKey); err != nil {
+		return domain.AuditRecord{}, err
+	}
+
+	var (
+		seq  int64
+		hash string
+	)
+	err = tx.QueryRowContext(ctx, "SELECT seq, hash FROM audit_log ORDER BY seq DESC LIMIT 1").Scan(&seq, &hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return domain.AuditRecord{}, err
+	}
+
+	rec.Seq = seq + 1
+	rec.PrevHash = hash
+	rec.Hash = rec.ComputeHash()
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO audit_log (seq, entity, action, entity_id, actor_id, timestamp, prev_hash, hash) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		rec.Seq, rec.Entity, rec.Action, rec.EntityID, rec.ActorID, rec.Timestamp, rec.PrevHash, rec.Hash)
+	if err != nil {
+		return domain.AuditRecord{}, err
+	}
+
+	return rec, tx.Commit()
+}
+
+// List returns up to limit records with seq greater than afterSeq, in chain order.
+func (a *Audit) List(ctx context.Context, afterSeq int64, limit int) ([]domain.AuditRecord, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT seq, entity, action, entity_id, actor_id, timestamp, prev_hash, hash FROM audit_log WHERE seq > $1 ORDER BY seq LIMIT $2",
+		afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := make([]domain.AuditRecord, 0)
+	for rows.Next() {
+		var rec domain.AuditRecord
+		if err := rows.Scan(&rec.Seq, &rec.Entity, &rec.Action, &rec.EntityID, &rec.ActorID, &rec.Timestamp, &rec.PrevHash, &rec.Hash); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+func (a *Audit) CreateCheckpoint(ctx context.Context, cp domain.AuditCheckpoint) error {
+	_, err := a.db.ExecContext(ctx, "INSERT INTO audit_checkpoints (seq, hash, signature, created_at) VALUES ($1, $2, $3, $4)",
+		cp.Seq, cp.Hash, cp.Signature, cp.CreatedAt)
+	return err
+}
+
+func (a *Audit) GetCheckpoints(ctx context.Context) ([]domain.AuditCheckpoint, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT seq, hash, signature, created_at FROM audit_checkpoints ORDER BY seq")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	checkpoints := make([]domain.AuditCheckpoint, 0)
+	for rows.Next() {
+		var cp domain.AuditCheckpoint
+		if err := rows.Scan(&cp.Seq, &cp.Hash, &cp.Signature, &cp.CreatedAt); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// Find returns records matching filter, newest first, together with the
+// total number of matches ignoring Limit and Offset.
+func (a *Audit) Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditRecord, int64, error) {
+	where, args := auditFilterQuery(filter)
+
+	var total int64
+	if err := a.db.QueryRowContext(ctx, "SELECT count(*) FROM audit_log"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT seq, entity, action, entity_id, actor_id, timestamp, prev_hash, hash FROM audit_log" + where + " ORDER BY seq DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := a.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	records := make([]domain.AuditRecord, 0)
+	for rows.Next() {
+		var rec domain.AuditRecord
+		if err := rows.Scan(&rec.Seq, &rec.Entity, &rec.Action, &rec.EntityID, &rec.ActorID, &rec.Timestamp, &rec.PrevHash, &rec.Hash); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, rec)
+	}
+	return records, total, rows.Err()
+}
+
+func auditFilterQuery(filter domain.AuditFilter) (string, []interface{}) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Entity != "" {
+		add("entity = $%d", filter.Entity)
+	}
+
+	if filter.Action != "" {
+		add("action = $%d", filter.Action)
+	}
+
+	if filter.EntityID != nil {
+		add("entity_id = $%d", *filter.EntityID)
+	}
+
+	if filter.ActorID != nil {
+		add("actor_id = $%d", *filter.ActorID)
+	}
+
+	if filter.From != nil {
+		add("timestamp >= $%d", *filter.From)
+	}
+
+	if filter.To != nil {
+		add("timestamp < $%d", *filter.To)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,295 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"lib/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type Authors struct {
+	db *sql.DB
+}
+
+func NewAuthors(db *sql.DB) *Authors {
+	return &Authors{
+		db: db,
+	}
+}
+
+func (a *Authors) Create(ctx context.Context, author domain.Author) (int64, error) {
+	var id int64
+	err := a.db.QueryRowContext(ctx, "INSERT INTO authors (name, bio) VALUES ($1, $2) RETURNING id",
+		author.Name, author.Bio).Scan(&id)
+	if err != nil {
+		return 0, translateError(err, nil)
+	}
+
+	return id, nil
+}
+
+func (a *Authors) GetAll(ctx context.Context) ([]domain.Author, error) {
+	rows, err := a.db.QueryContext(ctx, "SELECT id, name, bio, created_at FROM authors ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	authors := make([]domain.Author, 0)
+	for rows.Next() {
+		var author domain.Author
+		if err := rows.Scan(&author.ID, &author.Name, &author.Bio, &author.CreatedAt); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+	return authors, rows.Err()
+}
+
+func (a *Authors) GetByID(ctx context.Context, id int64) (domain.Author, error) {
+	var (
+		author  domain.Author
+		ratings domain.RatingStats
+	)
+	err := a.db.QueryRowContext(ctx, `SELECT a.id, a.name, a.bio, a.created_at,
+		coalesce(s.books, 0), coalesce(s.rated, 0), coalesce(s.average, 0)
+		FROM authors a LEFT JOIN author_rating_stats s ON s.author_id = a.id WHERE a.id = $1`, id).Scan(
+		&author.ID, &author.Name, &author.Bio, &author.CreatedAt, &ratings.Books, &ratings.Rated, &ratings.Average)
+	author.Ratings = &ratings
+
+	return author, translateError(err, domain.ErrAuthorNotFound)
+}
+
+// Update changes the author and refreshes the display author of every book
+// they contributed to.
+func (a *Authors) Update(ctx context.Context, id int64, author domain.Author) error {
+	return withTx(ctx, a.db, func(tx *sql.Tx) error {
+		books, err := lockAuthorBooks(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "UPDATE authors SET name = $1, bio = $2 WHERE id = $3", author.Name, author.Bio, id)
+		if err != nil {
+			return translateError(err, nil)
+		}
+
+		if err := requireAffected(res, domain.ErrAuthorNotFound); err != nil {
+			return err
+		}
+
+		return syncAuthorTextFor(ctx, tx, books)
+	})
+}
+
+// Delete removes an author. Authors still linked to books cannot be deleted.
+func (a *Authors) Delete(ctx context.Context, id int64) error {
+	res, err := a.db.ExecContext(ctx, "DELETE FROM authors WHERE id = $1", id)
+	if err != nil {
+		return translateError(err, nil)
+	}
+
+	return requireAffected(res, domain.ErrAuthorNotFound)
+}
+
+// Merge moves every book link from one author to another and deletes the
+// first one.
+func (a *Authors) Merge(ctx context.Context, from, into int64) error {
+	return withTx(ctx, a.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "SELECT id FROM authors WHERE id = ANY($1) FOR UPDATE", pq.Array([]int64{from, into}))
+		if err != nil {
+			return err
+		}
+
+		found := 0
+		for rows.Next() {
+			found++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if found != 2 {
+			return domain.ErrAuthorNotFound
+		}
+
+		books, err := lockAuthorBooks(ctx, tx, from, into)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO book_authors (book_id, author_id, role, position)
+			SELECT book_id, $2, role, position FROM book_authors WHERE author_id = $1
+			ON CONFLICT DO NOTHING`, from, into); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM book_authors WHERE author_id = $1", from); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM authors WHERE id = $1", from); err != nil {
+			return err
+		}
+
+		return syncAuthorTextFor(ctx, tx, books)
+	})
+}
+
+// setBookAuthors replaces the contributors of a book.
+func setBookAuthors(ctx context.Context, q querier, bookID int64, authors []domain.BookAuthor) error {
+	if _, err := q.ExecContext(ctx, "DELETE FROM book_authors WHERE book_id = $1", bookID); err != nil {
+		return err
+	}
+
+	for _, a := range authors {
+		_, err := q.ExecContext(ctx, "INSERT INTO book_authors (book_id, author_id, role, position) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING",
+			bookID, a.AuthorID, a.Role, a.Position)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+				return domain.ErrAuthorNotFound
+			}
+			return translateError(err, nil)
+		}
+	}
+
+	return nil
+}
+
+// linkAuthorByName makes the author with the given name, created on demand,
+// the only contributor of a book. Names match case-insensitively.
+func linkAuthorByName(ctx context.Context, q querier, bookID int64, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	var authorID int64
+	err := q.QueryRowContext(ctx, `INSERT INTO authors (name) VALUES ($1)
+		ON CONFLICT ((lower(name))) DO UPDATE SET name = authors.name
+		RETURNING id`, name).Scan(&authorID)
+	if err != nil {
+		return err
+	}
+
+	return setBookAuthors(ctx, q, bookID, []domain.BookAuthor{{AuthorID: authorID, Role: domain.AuthorRoleAuthor}})
+}
+
+// syncAuthorText keeps books.author, the display string served to older
+// clients, equal to the ordered names of the book's authors.
+func syncAuthorText(ctx context.Context, q querier, bookID int64) error {
+	_, err := q.ExecContext(ctx, `UPDATE books SET author = COALESCE((
+			SELECT string_agg(a.name, ', ' ORDER BY ba.position)
+			FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+			WHERE ba.book_id = books.id AND ba.role = 'author'
+		), author)
+		WHERE id = $1`, bookID)
+	return err
+}
+
+// lockAuthorBooks locks every book, live or trashed, linked to one of the
+// authors and returns its state before the change.
+func lockAuthorBooks(ctx context.Context, tx *sql.Tx, authorIDs ...int64) ([]domain.Book, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT "+bookColumns+` FROM books
+		WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = ANY($1))
+		ORDER BY id FOR UPDATE`, pq.Array(authorIDs))
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	books := make([]domain.Book, 0)
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return books, loadBookAuthors(ctx, tx, books)
+}
+
+// syncAuthorTextFor refreshes the display author of books locked by
+// lockAuthorBooks. Every book whose state changed gets a new version and a
+// book_revisions row, as if it had been edited directly.
+func syncAuthorTextFor(ctx context.Context, tx *sql.Tx, books []domain.Book) error {
+	for _, old := range books {
+		id := int64(old.ID)
+		if err := syncAuthorText(ctx, tx, id); err != nil {
+			return err
+		}
+
+		synced, err := readBook(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if len(domain.DiffBooks(old, synced)) == 0 {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE books SET version = version + 1 WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		updated, err := readBook(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := insertRevision(ctx, tx, domain.RevisionUpdate, old, updated); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadBookAuthors fills the Authors of every book in place.
+func loadBookAuthors(ctx context.Context, q querier, books []domain.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(books))
+	index := make(map[int64][]int, len(books))
+	for i, b := range books {
+		books[i].Authors = make([]domain.BookAuthor, 0)
+		ids = append(ids, int64(b.ID))
+		index[int64(b.ID)] = append(index[int64(b.ID)], i)
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT ba.book_id, ba.author_id, a.name, ba.role, ba.position
+		FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+		WHERE ba.book_id = ANY($1)
+		ORDER BY ba.book_id, ba.position`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookID int64
+			author domain.BookAuthor
+		)
+		if err := rows.Scan(&bookID, &author.AuthorID, &author.Name, &author.Role, &author.Position); err != nil {
+			return err
+		}
+
+		for _, i := range index[bookID] {
+			books[i].Authors = append(books[i].Authors, author)
+		}
+	}
+	return rows.Err()
+}
@@ -3,11 +3,84 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"lib/internal/domain"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
 )
 
+// bookColumns is the column list scanned by scanBook.
+const bookColumns = `id, name, author, isbn, rating, version, deleted_at, publisher_id,
+	(SELECT p.name FROM publishers p WHERE p.id = books.publisher_id), published_on, published_precision`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scannerFunc adapts a function to the scanner interface.
+type scannerFunc func(dest ...interface{}) error
+
+func (f scannerFunc) Scan(dest ...interface{}) error {
+	return f(dest...)
+}
+
+func scanBook(row scanner) (domain.Book, error) {
+	var (
+		book          domain.Book
+		isbn          sql.NullString
+		publisherName sql.NullString
+		publishedOn   sql.NullTime
+		precision     sql.NullString
+	)
+	err := row.Scan(&book.ID, &book.Name, &book.Author, &isbn, &book.Rating, &book.Version, &book.DeletedAt, &book.PublisherID,
+		&publisherName, &publishedOn, &precision)
+	if err != nil {
+		return book, err
+	}
+
+	book.SetISBN(isbn.String)
+	book.PublisherName = publisherName.String
+	if publishedOn.Valid {
+		d := domain.NewPartialDate(publishedOn.Time, precision.String)
+		book.SetPublishedOn(&d)
+	}
+
+	return book, nil
+}
+
+// publishedOnArgs splits a publication date into the published_on and
+// published_precision columns.
+func publishedOnArgs(d *domain.PartialDate) (interface{}, interface{}) {
+	if d == nil || d.IsZero() {
+		return nil, nil
+	}
+	return d.Time(), d.Precision()
+}
+
+// nullString stores an empty string as NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// bookError translates errors of book writes, naming the book constraints
+// a client can run into.
+func bookError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "books_publisher_id_fkey":
+			return domain.ErrPublisherNotFound
+		case pqErr.Code == uniqueViolation && pqErr.Constraint == "books_isbn_key":
+			return domain.ErrISBNTaken
+		}
+	}
+	return translateError(err, nil)
+}
+
 type Books struct {
 	db *sql.DB
 }
@@ -20,17 +93,179 @@ func NewBooks(db *sql.DB) *Books {
 
 func (b *Books) Create(ctx context.Context, book domain.Book) (int64, error) {
 	var id int64
-	err := b.db.QueryRow("INSERT INTO books (name, author, publisher, rating) VALUES ($1, $2, $3, $4) RETURNING id",
-		book.Name, book.Author, book.Publisher, book.Rating).Scan(&id)
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		var err error
+		id, err = createBook(ctx, tx, book)
+		return err
+	})
+
+	return id, err
+}
+
+// createBook inserts a book with its contributors and first revision.
+func createBook(ctx context.Context, tx *sql.Tx, book domain.Book) (int64, error) {
+	if err := resolvePublisher(ctx, tx, &book); err != nil {
+		return 0, err
+	}
+
+	var id int64
+	publishedOn, precision := publishedOnArgs(book.PublishedOn)
+	err := tx.QueryRowContext(ctx, `INSERT INTO books (name, author, isbn, rating, publisher_id, published_on, published_precision)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		book.Name, book.Author, nullString(book.ISBN13), book.Rating, book.PublisherID, publishedOn, precision).Scan(&id)
+	if err != nil {
+		return 0, bookError(err)
+	}
+
+	if len(book.Authors) > 0 {
+		err = setBookAuthors(ctx, tx, id, book.Authors)
+	} else {
+		err = linkAuthorByName(ctx, tx, id, book.Author)
+	}
 	if err != nil {
 		return 0, err
 	}
 
-	return id, nil
+	if err := syncAuthorText(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	created, err := readBook(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, insertRevision(ctx, tx, domain.RevisionCreate, domain.Book{}, created)
+}
+
+// GetAll lists the live books matching filter along with the availability
+// of their copies.
+func (b *Books) GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error) {
+	query, args := bookFilterQuery(filter)
+
+	books, err := b.list(ctx, "SELECT "+bookColumns+" FROM books WHERE "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return books, loadAvailability(ctx, b.db, books)
+}
+
+// Export calls fn for every live book matching filter, in the filter's
+// order, while reading them from the database. Contributors are read in the same query,
+// so no book is buffered. An error from fn stops the export.
+func (b *Books) Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error {
+	query, args := bookFilterQuery(filter)
+
+	rows, err := b.db.QueryContext(ctx, `SELECT `+bookColumns+`, (
+			SELECT json_agg(json_build_object('author_id', ba.author_id, 'name', a.name, 'role', ba.role, 'position', ba.position)
+				ORDER BY ba.position)
+			FROM book_authors ba JOIN authors a ON a.id = ba.author_id
+			WHERE ba.book_id = books.id
+		)
+		FROM books WHERE `+query, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var authors []byte
+		book, err := scanBook(scannerFunc(func(dest ...interface{}) error {
+			return rows.Scan(append(dest, &authors)...)
+		}))
+		if err != nil {
+			return err
+		}
+
+		book.Authors = make([]domain.BookAuthor, 0)
+		if authors != nil {
+			if err := json.Unmarshal(authors, &book.Authors); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// bookOrders maps listing orders onto ORDER BY clauses. Each ends with the
+// ID so that pages are stable.
+var bookOrders = map[string]string{
+	domain.BookSortID:     "id",
+	domain.BookSortNewest: "id DESC",
+	domain.BookSortRating: "rating DESC, id DESC",
+}
+
+// bookFilterQuery returns the WHERE condition selecting live books that
+// match filter, followed by its ORDER BY and LIMIT clauses.
+func bookFilterQuery(filter domain.BookFilter) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	args := make([]interface{}, 0)
+
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Query != "" {
+		add("(name ILIKE '%%' || $%[1]d || '%%' OR author ILIKE '%%' || $%[1]d || '%%')", escapeLike(filter.Query))
+	}
+
+	if filter.Name != "" {
+		add("name ILIKE '%%' || $%d || '%%'", escapeLike(filter.Name))
+	}
+
+	if filter.Author != "" {
+		add("author ILIKE '%%' || $%d || '%%'", escapeLike(filter.Author))
+	}
+
+	if filter.PublisherID != nil {
+		add("publisher_id = $%d", *filter.PublisherID)
+	}
+
+	if filter.MinRating != nil {
+		add("rating >= $%d", *filter.MinRating)
+	}
+
+	if filter.PublishedFrom != nil {
+		add("published_on >= make_date($%d::int, 1, 1)", *filter.PublishedFrom)
+	}
+
+	if filter.PublishedTo != nil {
+		add("published_on < make_date($%d::int + 1, 1, 1)", *filter.PublishedTo)
+	}
+
+	order, ok := bookOrders[filter.Sort]
+	if !ok {
+		order = bookOrders[domain.BookSortID]
+	}
+	query := strings.Join(conditions, " AND ") + " ORDER BY " + order
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	return query, args
+}
+
+// escapeLike makes s match literally inside a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// GetTrash returns soft-deleted books, most recently deleted first.
+func (b *Books) GetTrash(ctx context.Context) ([]domain.Book, error) {
+	return b.list(ctx, "SELECT "+bookColumns+" FROM books WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC")
 }
 
-func (b *Books) GetAll(ctx context.Context) ([]domain.Book, error) {
-	rows, err := b.db.QueryContext(ctx, "SELECT id, name, author, publisher, rating FROM books")
+func (b *Books) list(ctx context.Context, query string, args ...interface{}) ([]domain.Book, error) {
+	rows, err := b.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -39,26 +274,67 @@ func (b *Books) GetAll(ctx context.Context) ([]domain.Book, error) {
 
 	books := make([]domain.Book, 0)
 	for rows.Next() {
-		var book domain.Book
-		if err := rows.Scan(&book.ID, &book.Name, &book.Author, &book.Publisher, &book.Rating); err != nil {
+		book, err := scanBook(rows)
+		if err != nil {
 			return nil, err
 		}
 		books = append(books, book)
 	}
-	return books, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return books, loadBookAuthors(ctx, b.db, books)
 }
 
 func (b *Books) GetByID(ctx context.Context, id int64) (domain.Book, error) {
-	row := b.db.QueryRowContext(ctx, "SELECT id, name, author, publisher, rating FROM books WHERE id = $1", id)
+	row := b.db.QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books WHERE id = $1 AND deleted_at IS NULL", id)
+
+	book, err := scanBook(row)
+	if err != nil {
+		return domain.Book{}, translateError(err, domain.ErrBookNotFound)
+	}
 
-	var book domain.Book
-	if err := row.Scan(&book.ID, &book.Name, &book.Author, &book.Publisher, &book.Rating); err != nil {
+	books := []domain.Book{book}
+	if err := loadBookAuthors(ctx, b.db, books); err != nil {
 		return domain.Book{}, err
 	}
-	return book, nil
+	return books[0], nil
+}
+
+// GetByISBN looks up a live book by its ISBN-13.
+func (b *Books) GetByISBN(ctx context.Context, isbn string) (domain.Book, error) {
+	row := b.db.QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books WHERE isbn = $1 AND deleted_at IS NULL", isbn)
+
+	book, err := scanBook(row)
+	if err != nil {
+		return domain.Book{}, translateError(err, domain.ErrBookNotFound)
+	}
+
+	books := []domain.Book{book}
+	if err := loadBookAuthors(ctx, b.db, books); err != nil {
+		return domain.Book{}, err
+	}
+	return books[0], nil
+}
+
+// Update applies inp and bumps the book version. A non-zero version makes
+// the update conditional on the stored version, checked in the same
+// statement. It returns the new version. Every write below records a
+// book_revisions row in the same transaction.
+func (b *Books) Update(ctx context.Context, id int64, version int, inp domain.UpdateBook) (int, error) {
+	var newVersion int
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		var err error
+		newVersion, err = updateBook(ctx, tx, id, version, inp)
+		return err
+	})
+
+	return newVersion, err
 }
 
-func (b *Books) Update(ctx context.Context, id int64, inp domain.UpdateBook) error {
+// updateBook applies inp to a live book within tx and records the revision.
+func updateBook(ctx context.Context, tx *sql.Tx, id int64, version int, inp domain.UpdateBook) (int, error) {
 	setValues := make([]string, 0)
 	args := make([]interface{}, 0)
 	argsID := 1
@@ -75,9 +351,22 @@ func (b *Books) Update(ctx context.Context, id int64, inp domain.UpdateBook) err
 		argsID++
 	}
 
-	if inp.Publisher != nil {
-		setValues = append(setValues, fmt.Sprintf("publisher = $%d", argsID))
-		args = append(args, *inp.Publisher)
+	if inp.ISBN != nil {
+		setValues = append(setValues, fmt.Sprintf("isbn = $%d", argsID))
+		args = append(args, nullString(*inp.ISBN))
+		argsID++
+	}
+
+	if inp.PublishedOn != nil {
+		publishedOn, precision := publishedOnArgs(inp.PublishedOn)
+		setValues = append(setValues, fmt.Sprintf("published_on = $%d, published_precision = $%d", argsID, argsID+1))
+		args = append(args, publishedOn, precision)
+		argsID += 2
+	}
+
+	if inp.PublisherID != nil {
+		setValues = append(setValues, fmt.Sprintf("publisher_id = $%d", argsID))
+		args = append(args, sql.NullInt64{Int64: *inp.PublisherID, Valid: *inp.PublisherID != 0})
 		argsID++
 	}
 
@@ -87,16 +376,165 @@ func (b *Books) Update(ctx context.Context, id int64, inp domain.UpdateBook) err
 		argsID++
 	}
 
+	setValues = append(setValues, "version = version + 1")
 	setQuery := strings.Join(setValues, ", ")
 
-	query := fmt.Sprintf("UPDATE books SET %s WHERE id = $%d", setQuery, argsID)
-	args = append(args, id)
+	query := fmt.Sprintf("UPDATE books SET %s WHERE id = $%d AND ($%d = 0 OR version = $%d) RETURNING version",
+		setQuery, argsID, argsID+1, argsID+1)
+	args = append(args, id, version)
 
-	_, err := b.db.ExecContext(ctx, query, args...)
-	return err
+	old, err := lockBook(ctx, tx, id, false)
+	if err != nil {
+		return 0, err
+	}
+
+	var newVersion int
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&newVersion)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, domain.ErrBookVersionMismatch
+	}
+	if err != nil {
+		return 0, bookError(err)
+	}
+
+	switch {
+	case inp.Authors != nil:
+		err = setBookAuthors(ctx, tx, id, inp.Authors)
+	case inp.Author != nil:
+		err = linkAuthorByName(ctx, tx, id, *inp.Author)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if inp.Authors != nil || inp.Author != nil {
+		if err := syncAuthorText(ctx, tx, id); err != nil {
+			return 0, err
+		}
+	}
+
+	updated, err := readBook(ctx, tx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return newVersion, insertRevision(ctx, tx, domain.RevisionUpdate, old, updated)
 }
 
-func (b *Books) Delete(ctx context.Context, id int64) error {
-	_, err := b.db.ExecContext(ctx, "DELETE FROM books WHERE id = $1", id)
-	return err
+// Delete moves the book to the trash, conditionally on version when it is
+// non-zero. Trashed books are invisible to every other read until restored.
+func (b *Books) Delete(ctx context.Context, id int64, version int) error {
+	return withTx(ctx, b.db, func(tx *sql.Tx) error {
+		old, err := lockBook(ctx, tx, id, false)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "UPDATE books SET deleted_at = now(), version = version + 1 WHERE id = $1 AND ($2 = 0 OR version = $2)",
+			id, version)
+		if err != nil {
+			return translateError(err, nil)
+		}
+
+		if err := requireAffected(res, domain.ErrBookVersionMismatch); err != nil {
+			return err
+		}
+
+		deleted, err := readBook(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return insertRevision(ctx, tx, domain.RevisionDelete, old, deleted)
+	})
+}
+
+// Restore takes the book out of the trash.
+func (b *Books) Restore(ctx context.Context, id int64) error {
+	return withTx(ctx, b.db, func(tx *sql.Tx) error {
+		old, err := lockBook(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE books SET deleted_at = NULL, version = version + 1 WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		restored, err := readBook(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		return insertRevision(ctx, tx, domain.RevisionRestore, old, restored)
+	})
+}
+
+// lockBook reads the book for update within tx, either from the live
+// catalog or from the trash.
+func lockBook(ctx context.Context, tx *sql.Tx, id int64, trashed bool) (domain.Book, error) {
+	query := "SELECT " + bookColumns + " FROM books WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	if trashed {
+		query = "SELECT " + bookColumns + " FROM books WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE"
+	}
+
+	book, err := scanBook(tx.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return domain.Book{}, translateError(err, domain.ErrBookNotFound)
+	}
+
+	books := []domain.Book{book}
+	if err := loadBookAuthors(ctx, tx, books); err != nil {
+		return domain.Book{}, err
+	}
+
+	return books[0], nil
+}
+
+// readBook reads the current state of a book, trashed or not, with its
+// contributors.
+func readBook(ctx context.Context, q querier, id int64) (domain.Book, error) {
+	book, err := scanBook(q.QueryRowContext(ctx, "SELECT "+bookColumns+" FROM books WHERE id = $1", id))
+	if err != nil {
+		return domain.Book{}, translateError(err, domain.ErrBookNotFound)
+	}
+
+	books := []domain.Book{book}
+	if err := loadBookAuthors(ctx, q, books); err != nil {
+		return domain.Book{}, err
+	}
+
+	return books[0], nil
+}
+
+// Purge permanently deletes books trashed before the given time and
+// returns their IDs.
+func (b *Books) Purge(ctx context.Context, before time.Time) ([]int64, error) {
+	rows, err := b.db.QueryContext(ctx, "DELETE FROM books WHERE deleted_at < $1 RETURNING id", before)
+	if err != nil {
+		return nil, translateError(err, nil)
+	}
+
+	defer rows.Close()
+
+	ids := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// RefreshRatingStats recomputes the rating aggregates of authors and
+// publishers. Readers keep seeing the previous aggregates meanwhile.
+func (b *Books) RefreshRatingStats(ctx context.Context) error {
+	for _, view := range []string{"author_rating_stats", "publisher_rating_stats"} {
+		if _, err := b.db.ExecContext(ctx, "REFRESH MATERIALIZED VIEW CONCURRENTLY "+view); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,85 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"lib/internal/domain"
+)
+
+const coverColumns = "c.book_id, c.content_type, c.width, c.height, c.size, c.checksum, c.updated_at"
+
+type Covers struct {
+	db *sql.DB
+}
+
+func NewCovers(db *sql.DB) *Covers {
+	return &Covers{
+		db: db,
+	}
+}
+
+func scanCover(row scanner) (domain.Cover, error) {
+	var cover domain.Cover
+	err := row.Scan(&cover.BookID, &cover.ContentType, &cover.Width, &cover.Height, &cover.Size, &cover.Checksum, &cover.UpdatedAt)
+	return cover, err
+}
+
+// coverLockClass namespaces the advisory locks that serialize cover
+// replacements of a book.
+const coverLockClass = 1
+
+// Replace records the cover of a book outside the trash, replacing any
+// previous one, and returns it as stored. write stores the images; it runs
+// once the book is known to exist, with the book kept out of the trash and
+// other replacements of its cover waiting.
+func (c *Covers) Replace(ctx context.Context, cover domain.Cover, write func() error) (domain.Cover, error) {
+	err := withTx(ctx, c.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", coverLockClass, cover.BookID); err != nil {
+			return err
+		}
+
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT true FROM books WHERE id = $1 AND deleted_at IS NULL FOR SHARE", cover.BookID).Scan(&exists)
+		if err != nil {
+			return translateError(err, domain.ErrBookNotFound)
+		}
+
+		if err := write(); err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO book_covers (book_id, content_type, width, height, size, checksum)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (book_id) DO UPDATE SET
+				content_type = EXCLUDED.content_type,
+				width = EXCLUDED.width,
+				height = EXCLUDED.height,
+				size = EXCLUDED.size,
+				checksum = EXCLUDED.checksum,
+				updated_at = now()
+			RETURNING updated_at`,
+			cover.BookID, cover.ContentType, cover.Width, cover.Height, cover.Size, cover.Checksum).Scan(&cover.UpdatedAt)
+	})
+	if err != nil {
+		return domain.Cover{}, err
+	}
+
+	return cover, nil
+}
+
+// GetByBookID returns the cover of a book outside the trash.
+func (c *Covers) GetByBookID(ctx context.Context, bookID int64) (domain.Cover, error) {
+	cover, err := scanCover(c.db.QueryRowContext(ctx, "SELECT "+coverColumns+
+		" FROM book_covers c JOIN books b ON b.id = c.book_id WHERE c.book_id = $1 AND b.deleted_at IS NULL", bookID))
+	return cover, translateError(err, domain.ErrCoverNotFound)
+}
+
+func (c *Covers) Delete(ctx context.Context, bookID int64) error {
+	res, err := c.db.ExecContext(ctx, "DELETE FROM book_covers WHERE book_id = $1", bookID)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, domain.ErrCoverNotFound)
+}
@@ -0,0 +1,57 @@
+package psql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes translated into domain errors.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
+	checkViolation      = "23514"
+	notNullViolation    = "23502"
+)
+
+// translateError maps driver errors onto the domain error taxonomy.
+// sql.ErrNoRows becomes notFound; constraint violations become conflict or
+// validation errors. Anything else is returned unchanged.
+func translateError(err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) && notFound != nil {
+		return notFound
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code {
+	case uniqueViolation:
+		return fmt.Errorf("%w: %s", domain.ErrConflict, constraintDetail(pqErr))
+	case foreignKeyViolation:
+		return fmt.Errorf("%w: %s", domain.ErrConflict, constraintDetail(pqErr))
+	case checkViolation, notNullViolation:
+		return domain.NewValidationError(domain.FieldError{
+			Field:   pqErr.Column,
+			Message: pqErr.Message,
+		})
+	}
+
+	return err
+}
+
+func constraintDetail(pqErr *pq.Error) string {
+	if pqErr.Detail != "" {
+		return pqErr.Detail
+	}
+	return pqErr.Message
+}
@@ -0,0 +1,302 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const holdColumns = `h.id, h.book_id, h.user_id, b.name, h.status,
+	CASE WHEN h.status = 'waiting' THEN (SELECT count(*) FROM holds q WHERE q.book_id = h.book_id
+		AND q.status = 'waiting' AND (q.created_at, q.id) <= (h.created_at, h.id)) ELSE 0 END,
+	h.item_id, i.barcode, h.created_at, h.ready_at, h.expires_at, h.closed_at`
+
+const holdTables = "holds h JOIN books b ON b.id = h.book_id LEFT JOIN items i ON i.id = h.item_id"
+
+// Holds keeps the hold queues. Copies are allocated to the oldest waiting
+// hold of their title whenever they become available: on return, when a
+// hold is placed or cancelled, when a ready hold expires, and when a copy
+// is added or put back on the shelf.
+type Holds struct {
+	db *sql.DB
+}
+
+func NewHolds(db *sql.DB) *Holds {
+	return &Holds{
+		db: db,
+	}
+}
+
+func scanHold(row scanner) (domain.Hold, error) {
+	var (
+		hold    domain.Hold
+		barcode sql.NullString
+	)
+	err := row.Scan(&hold.ID, &hold.BookID, &hold.UserID, &hold.Title, &hold.Status, &hold.Position,
+		&hold.ItemID, &barcode, &hold.CreatedAt, &hold.ReadyAt, &hold.ExpiresAt, &hold.ClosedAt)
+	hold.Barcode = barcode.String
+	return hold, err
+}
+
+func readHold(ctx context.Context, q querier, id int64) (domain.Hold, error) {
+	hold, err := scanHold(q.QueryRowContext(ctx, "SELECT "+holdColumns+" FROM "+holdTables+" WHERE h.id = $1", id))
+	return hold, translateError(err, domain.ErrHoldNotFound)
+}
+
+// holdError translates errors of hold writes, naming the hold constraints
+// a client can run into.
+func holdError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "holds_user_id_fkey":
+			return domain.ErrUserNotFound
+		case pqErr.Code == uniqueViolation && pqErr.Constraint == "holds_user_book_active_key":
+			return domain.ErrHoldExists
+		}
+	}
+	return translateError(err, nil)
+}
+
+// Place queues a hold on a title outside the trash. A copy is allocated
+// to it right away if one is on the shelf; the holds made ready are
+// returned.
+func (h *Holds) Place(ctx context.Context, userID, bookID int64, policy domain.LoanPolicy) (domain.Hold, []domain.Hold, error) {
+	var (
+		hold  domain.Hold
+		ready []domain.Hold
+	)
+
+	err := withTx(ctx, h.db, func(tx *sql.Tx) error {
+		var id int64
+		err := tx.QueryRowContext(ctx, `INSERT INTO holds (book_id, user_id)
+			SELECT id, $2 FROM books WHERE id = $1 AND deleted_at IS NULL RETURNING id`, bookID, userID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrBookNotFound
+		}
+		if err != nil {
+			return holdError(err)
+		}
+
+		if ready, err = allocateHolds(ctx, tx, bookID, policy); err != nil {
+			return err
+		}
+
+		hold, err = readHold(ctx, tx, id)
+		return err
+	})
+
+	return hold, ready, err
+}
+
+// Cancel closes an active hold. A copy set aside for it goes to the next
+// hold in the queue; the holds made ready are returned. A non-zero userID
+// restricts the cancellation to holds of that user.
+func (h *Holds) Cancel(ctx context.Context, id, userID int64, policy domain.LoanPolicy) (domain.Hold, []domain.Hold, error) {
+	var (
+		hold  domain.Hold
+		ready []domain.Hold
+	)
+
+	err := withTx(ctx, h.db, func(tx *sql.Tx) error {
+		var (
+			owner, bookID int64
+			status        string
+			itemID        *int64
+		)
+		err := tx.QueryRowContext(ctx, "SELECT user_id, book_id, status, item_id FROM holds WHERE id = $1 FOR UPDATE", id).
+			Scan(&owner, &bookID, &status, &itemID)
+		if err != nil {
+			return translateError(err, domain.ErrHoldNotFound)
+		}
+
+		switch {
+		case userID != 0 && owner != userID:
+			return domain.ErrHoldNotFound
+		case status != domain.HoldWaiting && status != domain.HoldReady:
+			return domain.ErrHoldClosed
+		}
+
+		if ready, err = closeHold(ctx, tx, id, bookID, itemID, domain.HoldCancelled, policy); err != nil {
+			return err
+		}
+
+		hold, err = readHold(ctx, tx, id)
+		return err
+	})
+
+	return hold, ready, err
+}
+
+// Expire closes the ready holds whose pickup period has run out and passes
+// their copies on. It returns the expired holds and the holds made ready.
+func (h *Holds) Expire(ctx context.Context, policy domain.LoanPolicy) ([]domain.Hold, []domain.Hold, error) {
+	var expired, ready []domain.Hold
+
+	err := withTx(ctx, h.db, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id, book_id, item_id FROM holds
+			WHERE status = 'ready' AND expires_at < now() ORDER BY expires_at FOR UPDATE SKIP LOCKED`)
+		if err != nil {
+			return err
+		}
+
+		type due struct {
+			id, bookID int64
+			itemID     *int64
+		}
+
+		var holds []due
+		for rows.Next() {
+			var d due
+			if err := rows.Scan(&d.id, &d.bookID, &d.itemID); err != nil {
+				rows.Close()
+				return err
+			}
+			holds = append(holds, d)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, d := range holds {
+			allocated, err := closeHold(ctx, tx, d.id, d.bookID, d.itemID, domain.HoldExpired, policy)
+			if err != nil {
+				return err
+			}
+			ready = append(ready, allocated...)
+
+			hold, err := readHold(ctx, tx, d.id)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, hold)
+		}
+
+		return nil
+	})
+
+	return expired, ready, err
+}
+
+// closeHold gives a hold its final status and releases the copy set aside
+// for it, allocating it to the next hold of the title.
+func closeHold(ctx context.Context, tx *sql.Tx, id, bookID int64, itemID *int64, status string, policy domain.LoanPolicy) ([]domain.Hold, error) {
+	_, err := tx.ExecContext(ctx, "UPDATE holds SET status = $2, closed_at = now() WHERE id = $1", id, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if itemID == nil {
+		return nil, nil
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE items SET status = 'available' WHERE id = $1 AND status = 'on_hold'", *itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return allocateHolds(ctx, tx, bookID, policy)
+}
+
+// allocateHolds pairs the oldest waiting holds of a title with its
+// available lendable copies, setting each copy aside for the pickup period.
+// Allocations of a title are serialized on its books row, so that a
+// concurrent allocation never jumps the queue. It returns the holds made
+// ready.
+func allocateHolds(ctx context.Context, tx *sql.Tx, bookID int64, policy domain.LoanPolicy) ([]domain.Hold, error) {
+	var ready []domain.Hold
+
+	if _, err := tx.ExecContext(ctx, "SELECT 1 FROM books WHERE id = $1 FOR NO KEY UPDATE", bookID); err != nil {
+		return nil, err
+	}
+
+	for {
+		var holdID, itemID int64
+
+		err := tx.QueryRowContext(ctx, `SELECT id FROM holds WHERE book_id = $1 AND status = 'waiting'
+			ORDER BY created_at, id LIMIT 1 FOR UPDATE`, bookID).Scan(&holdID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ready, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = tx.QueryRowContext(ctx, `SELECT id FROM items WHERE book_id = $1 AND status = 'available' AND type = ANY($2)
+			ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED`, bookID, pq.Array(policy.LendableTypes())).Scan(&itemID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ready, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE items SET status = 'on_hold' WHERE id = $1", itemID); err != nil {
+			return nil, err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE holds SET status = 'ready', item_id = $2, ready_at = now(),
+			expires_at = now() + make_interval(secs => $3) WHERE id = $1`, holdID, itemID, policy.PickupPeriod.Seconds())
+		if err != nil {
+			return nil, err
+		}
+
+		hold, err := readHold(ctx, tx, holdID)
+		if err != nil {
+			return nil, err
+		}
+		ready = append(ready, hold)
+	}
+}
+
+func (h *Holds) GetByID(ctx context.Context, id int64) (domain.Hold, error) {
+	return readHold(ctx, h.db, id)
+}
+
+// GetAll lists the holds matching filter, oldest first.
+func (h *Holds) GetAll(ctx context.Context, filter domain.HoldFilter) ([]domain.Hold, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conds = append(conds, fmt.Sprintf("h.user_id = $%d", len(args)))
+	}
+	if filter.BookID != nil {
+		args = append(args, *filter.BookID)
+		conds = append(conds, fmt.Sprintf("h.book_id = $%d", len(args)))
+	}
+	if filter.Active {
+		conds = append(conds, "h.status IN ('waiting', 'ready')")
+	}
+
+	query := "SELECT " + holdColumns + " FROM " + holdTables
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := h.db.QueryContext(ctx, query+" ORDER BY h.created_at, h.id", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	holds := make([]domain.Hold, 0)
+	for rows.Next() {
+		hold, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, rows.Err()
+}
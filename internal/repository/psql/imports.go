@@ -0,0 +1,197 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"lib/internal/domain"
+)
+
+// errDryRun rolls back the transaction of a dry-run import.
+var errDryRun = errors.New("dry run")
+
+// Import creates or updates books in one transaction. A book whose ISBN is
+// already catalogued updates that book; any other book is created. Each
+// book is written under its own savepoint, so a rejected book is reported
+// in its result without aborting the others. Errors that are not about a
+// single book abort the whole batch. A dry run performs every write and
+// rolls them back.
+func (b *Books) Import(ctx context.Context, books []domain.CreateBookInput, dryRun bool) ([]domain.ImportResult, error) {
+	results := make([]domain.ImportResult, len(books))
+
+	err := withTx(ctx, b.db, func(tx *sql.Tx) error {
+		for i, book := range books {
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT import_book"); err != nil {
+				return err
+			}
+
+			created, err := importBook(ctx, tx, book)
+			if err != nil {
+				if !isBookError(err) {
+					return err
+				}
+
+				results[i].Err = err
+				if _, err := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_book"); err != nil {
+					return err
+				}
+				continue
+			}
+
+			results[i].Created = created
+			if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_book"); err != nil {
+				return err
+			}
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func importBook(ctx context.Context, tx *sql.Tx, inp domain.CreateBookInput) (bool, error) {
+	book := inp.Book()
+	if err := resolvePublisher(ctx, tx, &book); err != nil {
+		return false, err
+	}
+
+	if book.ISBN13 != "" {
+		var (
+			id      int64
+			trashed bool
+		)
+		err := tx.QueryRowContext(ctx, "SELECT id, deleted_at IS NOT NULL FROM books WHERE isbn = $1", book.ISBN13).Scan(&id, &trashed)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return false, err
+		case trashed:
+			return false, domain.ErrISBNTaken
+		default:
+			_, err := updateBook(ctx, tx, id, 0, importUpdate(book, inp.Rating))
+			return false, err
+		}
+	}
+
+	_, err := createBook(ctx, tx, book)
+	return true, err
+}
+
+// importUpdate overwrites a book with an imported one. Fields the import
+// left empty are kept; a nil rating means the import had none.
+func importUpdate(book domain.Book, rating *int) domain.UpdateBook {
+	inp := domain.UpdateBook{
+		Name:        &book.Name,
+		PublishedOn: book.PublishedOn,
+		PublisherID: book.PublisherID,
+		Rating:      rating,
+	}
+
+	if len(book.Authors) > 0 {
+		inp.Authors = book.Authors
+	} else if book.Author != "" {
+		inp.Author = &book.Author
+	}
+
+	return inp
+}
+
+// isBookError reports whether err rejects a single book rather than
+// signalling a database failure.
+func isBookError(err error) bool {
+	return errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrConflict) ||
+		errors.Is(err, domain.ErrValidation) || errors.Is(err, domain.ErrPreconditionFailed)
+}
+
+const importJobColumns = "id, status, format, dry_run, actor_id, report, error, created_at, finished_at"
+
+type Imports struct {
+	db *sql.DB
+}
+
+func NewImports(db *sql.DB) *Imports {
+	return &Imports{
+		db: db,
+	}
+}
+
+func (i *Imports) Create(ctx context.Context, job domain.ImportJob) (int64, error) {
+	var id int64
+	err := i.db.QueryRowContext(ctx, "INSERT INTO import_jobs (status, format, dry_run, actor_id) VALUES ($1, $2, $3, $4) RETURNING id",
+		job.Status, job.Format, job.DryRun, job.ActorID).Scan(&id)
+
+	return id, err
+}
+
+func (i *Imports) SetStatus(ctx context.Context, id int64, status string) error {
+	res, err := i.db.ExecContext(ctx, "UPDATE import_jobs SET status = $1 WHERE id = $2", status, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, domain.ErrImportNotFound)
+}
+
+// Finish records the final status of a job with its report or error.
+func (i *Imports) Finish(ctx context.Context, id int64, status string, report *domain.ImportReport, jobErr string) error {
+	var reportJSON []byte
+	if report != nil {
+		var err error
+		if reportJSON, err = json.Marshal(report); err != nil {
+			return err
+		}
+	}
+
+	res, err := i.db.ExecContext(ctx, "UPDATE import_jobs SET status = $1, report = $2, error = $3, finished_at = now() WHERE id = $4",
+		status, reportJSON, jobErr, id)
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(res, domain.ErrImportNotFound)
+}
+
+// FailUnfinished fails every pending or running job with jobErr and
+// returns how many there were.
+func (i *Imports) FailUnfinished(ctx context.Context, jobErr string) (int64, error) {
+	res, err := i.db.ExecContext(ctx, `UPDATE import_jobs SET status = 'failed', error = $1, finished_at = now()
+		WHERE status IN ('pending', 'running')`, jobErr)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (i *Imports) GetByID(ctx context.Context, id int64) (domain.ImportJob, error) {
+	var (
+		job    domain.ImportJob
+		report []byte
+	)
+
+	err := i.db.QueryRowContext(ctx, "SELECT "+importJobColumns+" FROM import_jobs WHERE id = $1", id).Scan(
+		&job.ID, &job.Status, &job.Format, &job.DryRun, &job.ActorID, &report, &job.Error, &job.CreatedAt, &job.FinishedAt)
+	if err != nil {
+		return job, translateError(err, domain.ErrImportNotFound)
+	}
+
+	if report != nil {
+		job.Report = new(domain.ImportReport)
+		if err := json.Unmarshal(report, job.Report); err != nil {
+			return job, err
+		}
+	}
+
+	return job, nil
+}
@@ -0,0 +1,56 @@
+package psql
+
+import (
+	"lib/internal/bookio"
+	"lib/internal/domain"
+	"strings"
+	"testing"
+)
+
+func TestImportUpdateKeepsMissingRating(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		rating *int
+	}{
+		{"csv without rating column", domain.ImportFormatCSV, "name,author\nDune,Frank Herbert\n", nil},
+		{"csv with empty rating", domain.ImportFormatCSV, "name,author,rating\nDune,Frank Herbert,\n", nil},
+		{"csv with zero rating", domain.ImportFormatCSV, "name,author,rating\nDune,Frank Herbert,0\n", intPtr(0)},
+		{"csv with rating", domain.ImportFormatCSV, "name,author,rating\nDune,Frank Herbert,4\n", intPtr(4)},
+		{"ndjson without rating", domain.ImportFormatNDJSON, `{"name":"Dune","author":"Frank Herbert"}` + "\n", nil},
+		{"ndjson with rating", domain.ImportFormatNDJSON, `{"name":"Dune","author":"Frank Herbert","rating":5}` + "\n", intPtr(5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := bookio.NewReader(tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			inp, err := src.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			update := importUpdate(inp.Book(), inp.Rating)
+			switch {
+			case tt.rating == nil && update.Rating != nil:
+				t.Errorf("rating = %d, want unchanged", *update.Rating)
+			case tt.rating != nil && update.Rating == nil:
+				t.Errorf("rating unchanged, want %d", *tt.rating)
+			case tt.rating != nil && *update.Rating != *tt.rating:
+				t.Errorf("rating = %d, want %d", *update.Rating, *tt.rating)
+			}
+
+			if update.Name == nil || *update.Name != "Dune" {
+				t.Errorf("name = %v, want Dune", update.Name)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,256 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const itemColumns = "i.id, i.book_id, i.barcode, i.type, i.shelf, i.condition, i.acquired_on, i.status, i.created_at"
+
+// liveItems joins items to their books, leaving out copies of trashed books.
+const liveItems = "items i JOIN books b ON b.id = i.book_id AND b.deleted_at IS NULL"
+
+type Items struct {
+	db *sql.DB
+}
+
+func NewItems(db *sql.DB) *Items {
+	return &Items{
+		db: db,
+	}
+}
+
+func scanItem(row scanner) (domain.Item, error) {
+	var (
+		item       domain.Item
+		code       string
+		acquiredOn sql.NullTime
+	)
+	err := row.Scan(&item.ID, &item.BookID, &code, &item.Type, &item.Shelf, &item.Condition, &acquiredOn, &item.Status, &item.CreatedAt)
+	if err != nil {
+		return item, err
+	}
+
+	item.SetBarcode(code)
+	if acquiredOn.Valid {
+		d := domain.NewPartialDate(acquiredOn.Time, domain.PrecisionDay)
+		item.AcquiredOn = &d
+	}
+
+	return item, nil
+}
+
+// dateArg stores a day-precision date in a DATE column.
+func dateArg(d *domain.PartialDate) interface{} {
+	if d == nil || d.IsZero() {
+		return nil
+	}
+	return d.Time()
+}
+
+// itemError translates errors of item writes, naming the item constraints
+// a client can run into.
+func itemError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "items_book_id_fkey":
+			return domain.ErrBookNotFound
+		case pqErr.Code == uniqueViolation && pqErr.Constraint == "items_barcode_key":
+			return domain.ErrBarcodeTaken
+		}
+	}
+	return translateError(err, nil)
+}
+
+// Create adds a copy of a book outside the trash. An available copy goes
+// to the oldest waiting hold of the title; the holds made ready are
+// returned.
+func (i *Items) Create(ctx context.Context, item domain.Item, policy domain.LoanPolicy) (int64, []domain.Hold, error) {
+	var (
+		id    int64
+		ready []domain.Hold
+	)
+
+	err := withTx(ctx, i.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO items (book_id, barcode, type, shelf, condition, acquired_on, status)
+			SELECT id, $2, $3, $4, $5, $6, $7 FROM books WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id`,
+			item.BookID, item.Barcode, item.Type, item.Shelf, item.Condition, dateArg(item.AcquiredOn), item.Status).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrBookNotFound
+		}
+		if err != nil {
+			return itemError(err)
+		}
+
+		if item.Status == domain.ItemAvailable {
+			ready, err = allocateHolds(ctx, tx, item.BookID, policy)
+		}
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return id, ready, nil
+}
+
+func (i *Items) GetAll(ctx context.Context, filter domain.ItemFilter) ([]domain.Item, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.BookID != nil {
+		args = append(args, *filter.BookID)
+		conds = append(conds, fmt.Sprintf("i.book_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, fmt.Sprintf("i.status = $%d", len(args)))
+	}
+
+	query := "SELECT " + itemColumns + " FROM " + liveItems
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := i.db.QueryContext(ctx, query+" ORDER BY i.book_id, i.id", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make([]domain.Item, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (i *Items) GetByID(ctx context.Context, id int64) (domain.Item, error) {
+	item, err := scanItem(i.db.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM "+liveItems+" WHERE i.id = $1", id))
+	return item, translateError(err, domain.ErrItemNotFound)
+}
+
+func (i *Items) GetByBarcode(ctx context.Context, code string) (domain.Item, error) {
+	item, err := scanItem(i.db.QueryRowContext(ctx, "SELECT "+itemColumns+" FROM "+liveItems+" WHERE i.barcode = $1", code))
+	return item, translateError(err, domain.ErrItemNotFound)
+}
+
+// Update replaces an item. It may be moved to another book outside the
+// trash, unless it is set aside for a hold. The status of an item on loan
+// or on hold is left to circulation. A copy left available goes to the
+// oldest waiting hold of its title; the holds made ready are returned.
+func (i *Items) Update(ctx context.Context, id int64, item domain.Item, policy domain.LoanPolicy) ([]domain.Hold, error) {
+	var ready []domain.Hold
+
+	err := withTx(ctx, i.db, func(tx *sql.Tx) error {
+		var (
+			bookID int64
+			status string
+		)
+		err := tx.QueryRowContext(ctx, "SELECT book_id, status FROM items WHERE id = $1 FOR UPDATE", id).Scan(&bookID, &status)
+		if err != nil {
+			return translateError(err, domain.ErrItemNotFound)
+		}
+
+		if status == domain.ItemOnHold && bookID != item.BookID {
+			return domain.ErrItemOnHold
+		}
+
+		var exists bool
+		err = tx.QueryRowContext(ctx, "SELECT true FROM books WHERE id = $1 AND deleted_at IS NULL FOR NO KEY UPDATE", item.BookID).Scan(&exists)
+		if err != nil {
+			return translateError(err, domain.ErrBookNotFound)
+		}
+
+		err = tx.QueryRowContext(ctx, `UPDATE items SET book_id = $1, barcode = $2, type = $3, shelf = $4, condition = $5, acquired_on = $6,
+			status = CASE WHEN status IN ('on_loan', 'on_hold') THEN status ELSE $7 END
+			WHERE id = $8 RETURNING status`,
+			item.BookID, item.Barcode, item.Type, item.Shelf, item.Condition, dateArg(item.AcquiredOn), item.Status, id).Scan(&status)
+		if err != nil {
+			return itemError(err)
+		}
+
+		if status == domain.ItemAvailable {
+			ready, err = allocateHolds(ctx, tx, item.BookID, policy)
+		}
+		return err
+	})
+
+	return ready, err
+}
+
+// Delete removes an item along with its loan history. Items on loan or
+// set aside for a hold cannot be deleted.
+func (i *Items) Delete(ctx context.Context, id int64) error {
+	return withTx(ctx, i.db, func(tx *sql.Tx) error {
+		var status string
+		err := tx.QueryRowContext(ctx, "SELECT status FROM items WHERE id = $1 FOR UPDATE", id).Scan(&status)
+		if err != nil {
+			return translateError(err, domain.ErrItemNotFound)
+		}
+
+		switch status {
+		case domain.ItemOnLoan:
+			return domain.ErrItemOnLoan
+		case domain.ItemOnHold:
+			return domain.ErrItemOnHold
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM items WHERE id = $1", id)
+		return itemError(err)
+	})
+}
+
+// loadAvailability fills the Availability of every book in place.
+func loadAvailability(ctx context.Context, q querier, books []domain.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(books))
+	index := make(map[int64][]int, len(books))
+	for i, b := range books {
+		books[i].Availability = &domain.Availability{}
+		ids = append(ids, int64(b.ID))
+		index[int64(b.ID)] = append(index[int64(b.ID)], i)
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT book_id, count(*), count(*) FILTER (WHERE status = 'available')
+		FROM items WHERE book_id = ANY($1) GROUP BY book_id`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookID int64
+			a      domain.Availability
+		)
+		if err := rows.Scan(&bookID, &a.Total, &a.Available); err != nil {
+			return err
+		}
+
+		for _, i := range index[bookID] {
+			*books[i].Availability = a
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,87 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"lib/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+const jobRunColumns = "id, job, instance, started_at, finished_at, coalesce(result, ''), error"
+
+// JobRuns keeps the history of scheduled job runs.
+type JobRuns struct {
+	db       *sql.DB
+	instance string
+}
+
+// NewJobRuns returns the history of runs, recording new runs as made by
+// instance.
+func NewJobRuns(db *sql.DB, instance string) *JobRuns {
+	return &JobRuns{
+		db:       db,
+		instance: instance,
+	}
+}
+
+func (j *JobRuns) Start(ctx context.Context, job string) (int64, error) {
+	var id int64
+	err := j.db.QueryRowContext(ctx, "INSERT INTO job_runs (job, instance) VALUES ($1, $2) RETURNING id", job, j.instance).Scan(&id)
+	return id, err
+}
+
+func (j *JobRuns) Finish(ctx context.Context, id int64, result string, runErr error) error {
+	var message string
+	if runErr != nil {
+		message = runErr.Error()
+	}
+
+	_, err := j.db.ExecContext(ctx, "UPDATE job_runs SET finished_at = now(), result = $2, error = $3 WHERE id = $1", id, result, message)
+	return err
+}
+
+// CloseStale fails the runs left without a result, except those in
+// running.
+func (j *JobRuns) CloseStale(ctx context.Context, running []int64) (int64, error) {
+	res, err := j.db.ExecContext(ctx, `UPDATE job_runs SET finished_at = now(), result = 'failure', error = 'abandoned'
+		WHERE finished_at IS NULL AND id <> ALL (coalesce($1::bigint[], '{}'))`, pq.Array(running))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func (j *JobRuns) GetAll(ctx context.Context, filter domain.JobRunFilter) ([]domain.JobRun, error) {
+	var args []interface{}
+
+	query := "SELECT " + jobRunColumns + " FROM job_runs"
+	if filter.Job != "" {
+		args = append(args, filter.Job)
+		query += fmt.Sprintf(" WHERE job = $%d", len(args))
+	}
+	query += " ORDER BY started_at DESC, id DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := j.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	runs := make([]domain.JobRun, 0)
+	for rows.Next() {
+		var run domain.JobRun
+		if err := rows.Scan(&run.ID, &run.Job, &run.Instance, &run.StartedAt, &run.FinishedAt, &run.Result, &run.Error); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
@@ -0,0 +1,173 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"lib/internal/domain"
+	"lib/pkg/money"
+
+	"github.com/lib/pq"
+)
+
+const ledgerColumns = "id, user_id, kind, amount, loan_id, accrued_on, note, recorded_by, created_at"
+
+// Ledger keeps the patron accounts. Entries are only ever inserted; the
+// table rejects updates and deletes.
+type Ledger struct {
+	db *sql.DB
+}
+
+func NewLedger(db *sql.DB) *Ledger {
+	return &Ledger{
+		db: db,
+	}
+}
+
+func scanLedgerEntry(row scanner) (domain.LedgerEntry, error) {
+	var (
+		entry     domain.LedgerEntry
+		accruedOn sql.NullTime
+	)
+	err := row.Scan(&entry.ID, &entry.UserID, &entry.Kind, &entry.Amount, &entry.LoanID, &accruedOn,
+		&entry.Note, &entry.RecordedBy, &entry.CreatedAt)
+	if err != nil {
+		return entry, err
+	}
+
+	if accruedOn.Valid {
+		d := domain.NewPartialDate(accruedOn.Time, domain.PrecisionDay)
+		entry.AccruedOn = &d
+	}
+
+	return entry, nil
+}
+
+// balance sums the ledger of a user.
+func balance(ctx context.Context, q querier, userID int64) (money.Amount, error) {
+	var b money.Amount
+	err := q.QueryRowContext(ctx, "SELECT coalesce(sum(amount), 0) FROM ledger_entries WHERE user_id = $1", userID).Scan(&b)
+	return b, err
+}
+
+// pendingFines selects the fines not charged yet: one row per day of a
+// loan past both its due date and the last day already charged, up to the
+// day of its return or today. Each day costs the daily rate of the item type until
+// the sum charged to the loan reaches the cap; the last day may cost less.
+// The rates are passed as arrays $1 to $3, see fineRates.
+const pendingFines = `
+	SELECT l.user_id, l.id AS loan_id, f.last_on + d.n AS accrued_on,
+		CASE WHEN r.cap = 0 THEN r.per_day ELSE least(r.per_day, r.cap - f.charged - (d.n - 1) * r.per_day) END AS amount
+	FROM loans l
+	JOIN items i ON i.id = l.item_id
+	JOIN unnest($1::text[], $2::bigint[], $3::bigint[]) AS r (type, per_day, cap) ON r.type = i.type
+	CROSS JOIN LATERAL (
+		SELECT coalesce(sum(e.amount), 0)::bigint AS charged, greatest(max(e.accrued_on), l.due_at::date) AS last_on
+		FROM ledger_entries e WHERE e.loan_id = l.id AND e.kind = 'fine'
+	) AS f
+	CROSS JOIN LATERAL generate_series(1, coalesce(l.returned_at, now())::date - f.last_on) AS d (n)
+	WHERE coalesce(l.returned_at, now())::date > l.due_at::date
+		AND (r.cap = 0 OR f.charged + (d.n - 1) * r.per_day < r.cap)`
+
+// fineRates returns the arguments of pendingFines: the fined item types
+// with their daily rates and caps.
+func fineRates(policy domain.FinePolicy) []interface{} {
+	types := policy.FinedTypes()
+	perDay := make([]int64, len(types))
+	caps := make([]int64, len(types))
+	for i, itemType := range types {
+		perDay[i] = int64(policy.Rates[itemType].PerDay)
+		caps[i] = int64(policy.Rates[itemType].Cap)
+	}
+
+	return []interface{}{pq.Array(types), pq.Array(perDay), pq.Array(caps)}
+}
+
+// owed returns the balance of a user with the fines their loans have run
+// up since the last accrual.
+func owed(ctx context.Context, q querier, userID int64, policy domain.FinePolicy) (money.Amount, error) {
+	b, err := balance(ctx, q, userID)
+	if err != nil || len(policy.FinedTypes()) == 0 {
+		return b, err
+	}
+
+	var pending money.Amount
+	err = q.QueryRowContext(ctx, "SELECT coalesce(sum(amount), 0)::bigint FROM ("+pendingFines+" AND l.user_id = $4) AS p",
+		append(fineRates(policy), userID)...).Scan(&pending)
+	return b + pending, err
+}
+
+// Accrue charges the fines of every overdue day not charged yet, however
+// long ago the loan was returned, so accrual catches up after missed runs.
+// Running it again charges nothing twice. It returns the number of new
+// entries.
+func (l *Ledger) Accrue(ctx context.Context, policy domain.FinePolicy) (int64, error) {
+	if len(policy.FinedTypes()) == 0 {
+		return 0, nil
+	}
+
+	res, err := l.db.ExecContext(ctx, `INSERT INTO ledger_entries (user_id, kind, amount, loan_id, accrued_on)
+		SELECT user_id, 'fine', amount, loan_id, accrued_on FROM (`+pendingFines+`) AS p
+		ON CONFLICT (loan_id, accrued_on) WHERE kind = 'fine' DO NOTHING`, fineRates(policy)...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Credit records a payment or a waiver of amount against the balance of
+// userID. Credits never take a balance below zero.
+func (l *Ledger) Credit(ctx context.Context, userID int64, kind string, amount money.Amount, note string, recordedBy int64) (domain.LedgerEntry, error) {
+	var entry domain.LedgerEntry
+
+	err := withTx(ctx, l.db, func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT true FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&exists)
+		if err != nil {
+			return translateError(err, domain.ErrUserNotFound)
+		}
+
+		owed, err := balance(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+
+		if amount > owed {
+			return domain.ErrCreditExceedsBalance
+		}
+
+		entry, err = scanLedgerEntry(tx.QueryRowContext(ctx, `INSERT INTO ledger_entries (user_id, kind, amount, note, recorded_by)
+			VALUES ($1, $2, $3, $4, nullif($5, 0)) RETURNING `+ledgerColumns, userID, kind, -amount, note, recordedBy))
+		return err
+	})
+
+	return entry, err
+}
+
+// GetAccount returns the balance and the ledger of a user.
+func (l *Ledger) GetAccount(ctx context.Context, userID int64) (domain.Account, error) {
+	account := domain.Account{UserID: userID, Entries: make([]domain.LedgerEntry, 0)}
+
+	var exists bool
+	err := l.db.QueryRowContext(ctx, "SELECT true FROM users WHERE id = $1", userID).Scan(&exists)
+	if err != nil {
+		return account, translateError(err, domain.ErrUserNotFound)
+	}
+
+	rows, err := l.db.QueryContext(ctx, "SELECT "+ledgerColumns+" FROM ledger_entries WHERE user_id = $1 ORDER BY id DESC", userID)
+	if err != nil {
+		return account, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		entry, err := scanLedgerEntry(rows)
+		if err != nil {
+			return account, err
+		}
+		account.Balance += entry.Amount
+		account.Entries = append(account.Entries, entry)
+	}
+	return account, rows.Err()
+}
@@ -0,0 +1,320 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const loanColumns = "l.id, l.item_id, l.user_id, i.book_id, b.name, i.barcode, l.checked_out_at, l.due_at, l.returned_at, l.renewals"
+
+const loanTables = "loans l JOIN items i ON i.id = l.item_id JOIN books b ON b.id = i.book_id"
+
+// Loans keeps the circulation record. Every state change runs in a
+// transaction that locks the item before its loan, so concurrent
+// checkouts, returns and renewals of the same copy are serialized.
+type Loans struct {
+	db *sql.DB
+}
+
+func NewLoans(db *sql.DB) *Loans {
+	return &Loans{
+		db: db,
+	}
+}
+
+func scanLoan(row scanner) (domain.Loan, error) {
+	var loan domain.Loan
+	err := row.Scan(&loan.ID, &loan.ItemID, &loan.UserID, &loan.BookID, &loan.Title, &loan.Barcode,
+		&loan.CheckedOutAt, &loan.DueAt, &loan.ReturnedAt, &loan.Renewals)
+	if err != nil {
+		return loan, err
+	}
+
+	loan.Overdue = loan.IsOverdue(time.Now())
+	return loan, nil
+}
+
+func readLoan(ctx context.Context, q querier, id int64) (domain.Loan, error) {
+	loan, err := scanLoan(q.QueryRowContext(ctx, "SELECT "+loanColumns+" FROM "+loanTables+" WHERE l.id = $1", id))
+	return loan, translateError(err, domain.ErrLoanNotFound)
+}
+
+// loanError translates errors of loan writes, naming the loan constraints
+// a client can run into.
+func loanError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == foreignKeyViolation && pqErr.Constraint == "loans_user_id_fkey":
+			return domain.ErrUserNotFound
+		case pqErr.Code == uniqueViolation && pqErr.Constraint == "loans_item_open_key":
+			return domain.ErrItemNotAvailable
+		}
+	}
+	return translateError(err, nil)
+}
+
+// lockedItem is a copy locked for a circulation change.
+type lockedItem struct {
+	id, bookID       int64
+	itemType, status string
+}
+
+// lockItem locks a copy of a book outside the trash, found by ID or, when
+// itemID is 0, by barcode.
+func lockItem(ctx context.Context, tx *sql.Tx, itemID int64, barcode string) (lockedItem, error) {
+	cond, arg := "i.id = $1", interface{}(itemID)
+	if itemID == 0 {
+		cond, arg = "i.barcode = $1", barcode
+	}
+
+	var item lockedItem
+	err := tx.QueryRowContext(ctx, "SELECT i.id, i.book_id, i.type, i.status FROM items i"+
+		" JOIN books b ON b.id = i.book_id AND b.deleted_at IS NULL WHERE "+cond+" FOR UPDATE OF i", arg).
+		Scan(&item.id, &item.bookID, &item.itemType, &item.status)
+
+	return item, translateError(err, domain.ErrItemNotFound)
+}
+
+// Checkout lends an item to a user for the loan period of its type. An
+// item set aside for a hold is only lent to the holder, and any hold of
+// the user on the title is fulfilled by the loan. Users owing more than
+// the policy allows, counting the fines not accrued yet, cannot borrow.
+func (l *Loans) Checkout(ctx context.Context, itemID int64, barcode string, userID int64, policy domain.LoanPolicy) (domain.Loan, error) {
+	var loan domain.Loan
+
+	err := withTx(ctx, l.db, func(tx *sql.Tx) error {
+		// The user is locked before the item, as in every transaction that
+		// locks both, so that concurrent checkouts and credits see each
+		// other's effect on the balance.
+		var exists bool
+		err := tx.QueryRowContext(ctx, "SELECT true FROM users WHERE id = $1 FOR UPDATE", userID).Scan(&exists)
+		if err != nil {
+			return translateError(err, domain.ErrUserNotFound)
+		}
+
+		item, err := lockItem(ctx, tx, itemID, barcode)
+		if err != nil {
+			return err
+		}
+
+		period, err := policy.Period(item.itemType)
+		if err != nil {
+			return err
+		}
+
+		owes, err := owed(ctx, tx, userID, policy.Fines)
+		if err != nil {
+			return err
+		}
+
+		if owes > policy.MaxBalance {
+			return domain.ErrBalanceLimit
+		}
+
+		switch item.status {
+		case domain.ItemAvailable:
+			_, err = tx.ExecContext(ctx, `UPDATE holds SET status = 'fulfilled', closed_at = now()
+				WHERE book_id = $1 AND user_id = $2 AND status = 'waiting'`, item.bookID, userID)
+			if err != nil {
+				return err
+			}
+		case domain.ItemOnHold:
+			res, err := tx.ExecContext(ctx, `UPDATE holds SET status = 'fulfilled', closed_at = now()
+				WHERE item_id = $1 AND user_id = $2 AND status = 'ready'`, item.id, userID)
+			if err != nil {
+				return err
+			}
+			if err := requireAffected(res, domain.ErrItemOnHold); err != nil {
+				return err
+			}
+		default:
+			return domain.ErrItemNotAvailable
+		}
+
+		var id int64
+		err = tx.QueryRowContext(ctx, `INSERT INTO loans (item_id, user_id, due_at)
+			VALUES ($1, $2, now() + make_interval(secs => $3)) RETURNING id`,
+			item.id, userID, period.Seconds()).Scan(&id)
+		if err != nil {
+			return loanError(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE items SET status = 'on_loan' WHERE id = $1", item.id); err != nil {
+			return err
+		}
+
+		loan, err = readLoan(ctx, tx, id)
+		return err
+	})
+
+	return loan, err
+}
+
+// Return closes an open loan and makes its item available again, setting
+// it aside for the next hold on the title if there is one. The holds made
+// ready are returned with the loan.
+func (l *Loans) Return(ctx context.Context, id int64, policy domain.LoanPolicy) (domain.Loan, []domain.Hold, error) {
+	var (
+		loan  domain.Loan
+		ready []domain.Hold
+	)
+
+	err := withTx(ctx, l.db, func(tx *sql.Tx) error {
+		var itemID, bookID int64
+		err := tx.QueryRowContext(ctx, "SELECT l.item_id, i.book_id FROM loans l JOIN items i ON i.id = l.item_id WHERE l.id = $1", id).
+			Scan(&itemID, &bookID)
+		if err != nil {
+			return translateError(err, domain.ErrLoanNotFound)
+		}
+
+		if _, err := tx.ExecContext(ctx, "SELECT 1 FROM items WHERE id = $1 FOR UPDATE", itemID); err != nil {
+			return err
+		}
+
+		var returnedAt *time.Time
+		err = tx.QueryRowContext(ctx, "SELECT returned_at FROM loans WHERE id = $1 FOR UPDATE", id).Scan(&returnedAt)
+		if err != nil {
+			return translateError(err, domain.ErrLoanNotFound)
+		}
+
+		if returnedAt != nil {
+			return domain.ErrLoanReturned
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE loans SET returned_at = now() WHERE id = $1", id); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE items SET status = 'available' WHERE id = $1", itemID); err != nil {
+			return err
+		}
+
+		if ready, err = allocateHolds(ctx, tx, bookID, policy); err != nil {
+			return err
+		}
+
+		loan, err = readLoan(ctx, tx, id)
+		return err
+	})
+
+	return loan, ready, err
+}
+
+// Renew extends an open loan by the loan period of its item type, counted
+// from now but never shortening the loan. Loans of titles other readers
+// are waiting for cannot be renewed. A non-zero userID restricts the
+// renewal to loans of that user.
+func (l *Loans) Renew(ctx context.Context, id, userID int64, policy domain.LoanPolicy) (domain.Loan, error) {
+	var loan domain.Loan
+
+	err := withTx(ctx, l.db, func(tx *sql.Tx) error {
+		var itemID int64
+		err := tx.QueryRowContext(ctx, "SELECT item_id FROM loans WHERE id = $1", id).Scan(&itemID)
+		if err != nil {
+			return translateError(err, domain.ErrLoanNotFound)
+		}
+
+		if _, err := tx.ExecContext(ctx, "SELECT 1 FROM items WHERE id = $1 FOR UPDATE", itemID); err != nil {
+			return err
+		}
+
+		var (
+			owner      int64
+			returnedAt *time.Time
+			renewals   int
+			itemType   string
+			held       bool
+		)
+		err = tx.QueryRowContext(ctx, `SELECT l.user_id, l.returned_at, l.renewals, i.type,
+			EXISTS (SELECT 1 FROM holds h WHERE h.book_id = i.book_id AND h.status = 'waiting')
+			FROM loans l JOIN items i ON i.id = l.item_id WHERE l.id = $1 FOR UPDATE OF l`, id).
+			Scan(&owner, &returnedAt, &renewals, &itemType, &held)
+		if err != nil {
+			return translateError(err, domain.ErrLoanNotFound)
+		}
+
+		switch {
+		case userID != 0 && owner != userID:
+			return domain.ErrLoanNotFound
+		case returnedAt != nil:
+			return domain.ErrLoanReturned
+		case renewals >= policy.MaxRenewals:
+			return domain.ErrRenewalLimit
+		case held:
+			return domain.ErrRenewalHeld
+		}
+
+		period, err := policy.Period(itemType)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE loans SET renewals = renewals + 1,
+			due_at = greatest(due_at, now() + make_interval(secs => $2)) WHERE id = $1`, id, period.Seconds())
+		if err != nil {
+			return err
+		}
+
+		loan, err = readLoan(ctx, tx, id)
+		return err
+	})
+
+	return loan, err
+}
+
+func (l *Loans) GetByID(ctx context.Context, id int64) (domain.Loan, error) {
+	return readLoan(ctx, l.db, id)
+}
+
+// GetAll lists the loans matching filter, most recent first.
+func (l *Loans) GetAll(ctx context.Context, filter domain.LoanFilter) ([]domain.Loan, error) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		conds = append(conds, fmt.Sprintf("l.user_id = $%d", len(args)))
+	}
+	if filter.ItemID != nil {
+		args = append(args, *filter.ItemID)
+		conds = append(conds, fmt.Sprintf("l.item_id = $%d", len(args)))
+	}
+	if filter.Open || filter.Overdue {
+		conds = append(conds, "l.returned_at IS NULL")
+	}
+	if filter.Overdue {
+		conds = append(conds, "l.due_at < now()")
+	}
+
+	query := "SELECT " + loanColumns + " FROM " + loanTables
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := l.db.QueryContext(ctx, query+" ORDER BY l.checked_out_at DESC, l.id DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	loans := make([]domain.Loan, 0)
+	for rows.Next() {
+		loan, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		loans = append(loans, loan)
+	}
+	return loans, rows.Err()
+}
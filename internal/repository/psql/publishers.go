@@ -0,0 +1,111 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"lib/internal/domain"
+)
+
+const publisherColumns = "id, name, country, website, created_at"
+
+type Publishers struct {
+	db *sql.DB
+}
+
+func NewPublishers(db *sql.DB) *Publishers {
+	return &Publishers{
+		db: db,
+	}
+}
+
+func scanPublisher(row scanner) (domain.Publisher, error) {
+	var publisher domain.Publisher
+	err := row.Scan(&publisher.ID, &publisher.Name, &publisher.Country, &publisher.Website, &publisher.CreatedAt)
+	return publisher, err
+}
+
+func (p *Publishers) Create(ctx context.Context, publisher domain.Publisher) (int64, error) {
+	var id int64
+	err := p.db.QueryRowContext(ctx, "INSERT INTO publishers (name, country, website) VALUES ($1, $2, $3) RETURNING id",
+		publisher.Name, publisher.Country, publisher.Website).Scan(&id)
+	if err != nil {
+		return 0, translateError(err, nil)
+	}
+
+	return id, nil
+}
+
+func (p *Publishers) GetAll(ctx context.Context) ([]domain.Publisher, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT "+publisherColumns+" FROM publishers ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	publishers := make([]domain.Publisher, 0)
+	for rows.Next() {
+		publisher, err := scanPublisher(rows)
+		if err != nil {
+			return nil, err
+		}
+		publishers = append(publishers, publisher)
+	}
+	return publishers, rows.Err()
+}
+
+func (p *Publishers) GetByID(ctx context.Context, id int64) (domain.Publisher, error) {
+	var (
+		publisher domain.Publisher
+		ratings   domain.RatingStats
+	)
+	err := p.db.QueryRowContext(ctx, `SELECT p.id, p.name, p.country, p.website, p.created_at,
+		coalesce(s.books, 0), coalesce(s.rated, 0), coalesce(s.average, 0)
+		FROM publishers p LEFT JOIN publisher_rating_stats s ON s.publisher_id = p.id WHERE p.id = $1`, id).Scan(
+		&publisher.ID, &publisher.Name, &publisher.Country, &publisher.Website, &publisher.CreatedAt,
+		&ratings.Books, &ratings.Rated, &ratings.Average)
+	publisher.Ratings = &ratings
+
+	return publisher, translateError(err, domain.ErrPublisherNotFound)
+}
+
+func (p *Publishers) Update(ctx context.Context, id int64, publisher domain.Publisher) error {
+	res, err := p.db.ExecContext(ctx, "UPDATE publishers SET name = $1, country = $2, website = $3 WHERE id = $4",
+		publisher.Name, publisher.Country, publisher.Website, id)
+	if err != nil {
+		return translateError(err, nil)
+	}
+
+	return requireAffected(res, domain.ErrPublisherNotFound)
+}
+
+// Delete removes a publisher. Publishers still referenced by books, trashed
+// ones included, cannot be deleted.
+func (p *Publishers) Delete(ctx context.Context, id int64) error {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM publishers WHERE id = $1", id)
+	if err != nil {
+		return translateError(err, nil)
+	}
+
+	return requireAffected(res, domain.ErrPublisherNotFound)
+}
+
+// resolvePublisher sets the PublisherID of a book that only names its
+// publisher, creating the publisher on demand. Names match
+// case-insensitively.
+func resolvePublisher(ctx context.Context, q querier, book *domain.Book) error {
+	if book.PublisherID != nil || book.PublisherName == "" {
+		return nil
+	}
+
+	var id int64
+	err := q.QueryRowContext(ctx, `INSERT INTO publishers (name) VALUES ($1)
+		ON CONFLICT ((lower(name))) DO UPDATE SET name = publishers.name
+		RETURNING id`, book.PublisherName).Scan(&id)
+	if err != nil {
+		return translateError(err, nil)
+	}
+
+	book.PublisherID = &id
+	return nil
+}
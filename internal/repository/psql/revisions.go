@@ -0,0 +1,100 @@
+package psql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"lib/internal/domain"
+)
+
+const revisionColumns = "r.id, r.book_id, r.version, r.action, r.snapshot, r.diff, r.actor_id, r.created_at"
+
+// insertRevision records the transition from old to new within tx. The
+// acting user is taken from ctx.
+func insertRevision(ctx context.Context, tx *sql.Tx, action string, old, new domain.Book) error {
+	snapshot, err := json.Marshal(new)
+	if err != nil {
+		return err
+	}
+
+	diff, err := json.Marshal(domain.DiffBooks(old, new))
+	if err != nil {
+		return err
+	}
+
+	actorID, _ := domain.UserIDFromContext(ctx)
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO book_revisions (book_id, version, action, snapshot, diff, actor_id) VALUES ($1, $2, $3, $4, $5, $6)",
+		new.ID, new.Version, action, snapshot, diff, actorID)
+	return err
+}
+
+func scanRevision(row scanner) (domain.BookRevision, error) {
+	var (
+		rev            domain.BookRevision
+		snapshot, diff []byte
+	)
+
+	if err := row.Scan(&rev.ID, &rev.BookID, &rev.Version, &rev.Action, &snapshot, &diff, &rev.ActorID, &rev.CreatedAt); err != nil {
+		return rev, err
+	}
+
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return rev, err
+	}
+
+	return rev, json.Unmarshal(diff, &rev.Diff)
+}
+
+// liveRevisions selects the revisions of books outside the trash.
+const liveRevisions = "SELECT " + revisionColumns + " FROM book_revisions r JOIN books b ON b.id = r.book_id AND b.deleted_at IS NULL"
+
+// requireLiveBook fails with ErrBookNotFound unless the book exists outside
+// the trash.
+func requireLiveBook(ctx context.Context, q querier, id int64) error {
+	var exists bool
+	err := q.QueryRowContext(ctx, "SELECT true FROM books WHERE id = $1 AND deleted_at IS NULL", id).Scan(&exists)
+	return translateError(err, domain.ErrBookNotFound)
+}
+
+// GetRevisions returns the revision history of a book outside the trash,
+// newest first.
+func (b *Books) GetRevisions(ctx context.Context, bookID int64) ([]domain.BookRevision, error) {
+	if err := requireLiveBook(ctx, b.db, bookID); err != nil {
+		return nil, err
+	}
+
+	rows, err := b.db.QueryContext(ctx, liveRevisions+" WHERE r.book_id = $1 ORDER BY r.version DESC", bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revisions := make([]domain.BookRevision, 0)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision returns a revision of a book outside the trash.
+func (b *Books) GetRevision(ctx context.Context, bookID int64, version int) (domain.BookRevision, error) {
+	row := b.db.QueryRowContext(ctx, liveRevisions+" WHERE r.book_id = $1 AND r.version = $2", bookID, version)
+
+	rev, err := scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		if err := requireLiveBook(ctx, b.db, bookID); err != nil {
+			return domain.BookRevision{}, err
+		}
+	}
+	if err != nil {
+		return domain.BookRevision{}, translateError(err, domain.ErrRevisionNotFound)
+	}
+	return rev, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"lib/internal/domain"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
@@ -29,19 +30,25 @@ func (t *Token) Create(ctx context.Context, token domain.RefreshSession) error {
 func (t *Token) Get(ctx context.Context, token string) (domain.RefreshSession, error) {
 	var session domain.RefreshSession
 
-	err := t.db.QueryRow("SELECT id, user_id, token, expires_at FROM refresh_tokens WHERE token=$1", token).Scan(
+	err := t.db.QueryRowContext(ctx, "SELECT id, user_id, token, expires_at FROM refresh_tokens WHERE token=$1", token).Scan(
 		&session.ID, &session.UserID, &session.Token, &session.ExpiresAt,
 	)
-	if err == sql.ErrNoRows {
-		logrus.Info("refresh token no rows")
-		return session, nil
-	}
 	if err != nil {
 		logrus.Info("refresh token error")
-		return session, err
+		return session, translateError(err, domain.ErrRefreshTokenNotFound)
 	}
 
 	_, err = t.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE token=$1", token)
 
 	return session, err
 }
+
+// DeleteExpired removes the sessions that expired before the given time.
+func (t *Token) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := t.db.ExecContext(ctx, "DELETE FROM refresh_tokens WHERE expires_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
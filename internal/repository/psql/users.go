@@ -3,7 +3,10 @@ package psql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"lib/internal/domain"
+
+	"github.com/lib/pq"
 )
 
 type User struct {
@@ -17,7 +20,14 @@ func NewUsers(db *sql.DB) *User {
 func (r *User) Create(ctx context.Context, user domain.User) error {
 	_, err := r.db.ExecContext(ctx, "INSERT INTO users (name, email, password, registered_at) VALUES ($1, $2, $3, $4)",
 		user.Name, user.Email, user.Password, user.RegisteredAt)
-	return err
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return domain.ErrUserAlreadyRegistered
+		}
+	}
+
+	return translateError(err, nil)
 }
 
 func (r *User) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
@@ -25,5 +35,13 @@ func (r *User) GetByCredentials(ctx context.Context, email, password string) (do
 	err := r.db.QueryRowContext(ctx, "SELECT id, name, email, password, registered_at FROM users WHERE email=$1 AND password=$2",
 		email, password).Scan(&user.ID, &user.Name, &user.Email, &user.Password, &user.RegisteredAt)
 
-	return user, err
+	return user, translateError(err, domain.ErrUserNotFound)
+}
+
+func (r *User) GetByID(ctx context.Context, id int64) (domain.User, error) {
+	var user domain.User
+	err := r.db.QueryRowContext(ctx, "SELECT id, name, email, role, registered_at FROM users WHERE id=$1",
+		id).Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.RegisteredAt)
+
+	return user, translateError(err, domain.ErrUserNotFound)
 }
@@ -2,10 +2,219 @@ package service
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"lib/internal/domain"
+	"time"
 
 	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
 )
 
+// verifyBatchSize is the number of records read per query while verifying.
+const verifyBatchSize = 1000
+
+// Audit entities and actions beyond the set defined by the audit_logger
+// service. They are kept in the local audit trail only.
+const (
+	EntityAuthor    = "AUTHOR"
+	EntityPublisher = "PUBLISHER"
+	EntityImport    = "IMPORT"
+	EntityCover     = "COVER"
+	EntityItem      = "ITEM"
+	EntityLoan      = "LOAN"
+	EntityHold      = "HOLD"
+	EntityLedger    = "LEDGER"
+
+	ActionRestore  = "RESTORE"
+	ActionPurge    = "PURGE"
+	ActionMerge    = "MERGE"
+	ActionCheckout = "CHECKOUT"
+	ActionReturn   = "RETURN"
+	ActionRenew    = "RENEW"
+	ActionCancel   = "CANCEL"
+	ActionExpire   = "EXPIRE"
+	ActionPayment  = "PAYMENT"
+	ActionWaive    = "WAIVE"
+	ActionExport   = "EXPORT"
+)
+
 type AuditClient interface {
 	SendLogRequest(ctx context.Context, req audit.LogItem) error
 }
+
+type AuditRepository interface {
+	Append(ctx context.Context, rec domain.AuditRecord) (domain.AuditRecord, error)
+	List(ctx context.Context, afterSeq int64, limit int) ([]domain.AuditRecord, error)
+	CreateCheckpoint(ctx context.Context, cp domain.AuditCheckpoint) error
+	GetCheckpoints(ctx context.Context) ([]domain.AuditCheckpoint, error)
+	Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditRecord, int64, error)
+}
+
+// AuditTrail records every audit event in the local hash-chained store and
+// then forwards it to the remote audit service. It satisfies AuditClient,
+// so services feed it from the same places they used to call the remote
+// client directly.
+type AuditTrail struct {
+	repo   AuditRepository
+	remote AuditClient
+
+	secret             []byte
+	checkpointInterval int64
+}
+
+func NewAuditTrail(repo AuditRepository, remote AuditClient, secret []byte, checkpointInterval int) *AuditTrail {
+	return &AuditTrail{
+		repo:               repo,
+		remote:             remote,
+		secret:             secret,
+		checkpointInterval: int64(checkpointInterval),
+	}
+}
+
+func (a *AuditTrail) SendLogRequest(ctx context.Context, req audit.LogItem) error {
+	actorID, _ := domain.UserIDFromContext(ctx)
+
+	rec, err := a.repo.Append(ctx, domain.AuditRecord{
+		Entity:    req.Entity,
+		Action:    req.Action,
+		EntityID:  req.EntityID,
+		ActorID:   actorID,
+		Timestamp: req.Timestamp.UTC().Truncate(time.Microsecond),
+	})
+	if err != nil {
+		return err
+	}
+
+	if a.checkpointInterval > 0 && rec.Seq%a.checkpointInterval == 0 {
+		if err := a.repo.CreateCheckpoint(ctx, domain.AuditCheckpoint{
+			Seq:       rec.Seq,
+			Hash:      rec.Hash,
+			Signature: a.sign(rec.Seq, rec.Hash),
+			CreatedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !remoteSupports(req) {
+		return nil
+	}
+
+	return a.remote.SendLogRequest(ctx, req)
+}
+
+// remoteSupports reports whether the remote audit service can represent req.
+func remoteSupports(req audit.LogItem) bool {
+	if _, err := audit.ToPbEntity(req.Entity); err != nil {
+		return false
+	}
+
+	_, err := audit.ToPbAction(req.Action)
+	return err == nil
+}
+
+// Find returns audit records matching filter and the total match count.
+func (a *AuditTrail) Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditRecord, int64, error) {
+	return a.repo.Find(ctx, filter)
+}
+
+// BookHistory returns every audit record about the book with the given id.
+func (a *AuditTrail) BookHistory(ctx context.Context, id int64) ([]domain.AuditRecord, error) {
+	records, _, err := a.repo.Find(ctx, domain.AuditFilter{
+		Entity:   audit.ENTITY_BOOK,
+		EntityID: &id,
+	})
+
+	return records, err
+}
+
+// Verify walks the whole chain and reports the first record whose hash, link
+// to its predecessor or checkpoint signature does not match.
+func (a *AuditTrail) Verify(ctx context.Context) (domain.AuditVerification, error) {
+	var report domain.AuditVerification
+
+	checkpoints, err := a.repo.GetCheckpoints(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	signed := make(map[int64]domain.AuditCheckpoint, len(checkpoints))
+	for _, cp := range checkpoints {
+		signed[cp.Seq] = cp
+	}
+
+	var (
+		lastSeq  int64
+		lastHash string
+	)
+	for {
+		records, err := a.repo.List(ctx, lastSeq, verifyBatchSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, rec := range records {
+			if reason := a.check(rec, lastSeq, lastHash, signed); reason != "" {
+				report.BrokenAt = rec.Seq
+				report.Reason = reason
+				return report, nil
+			}
+
+			if _, ok := signed[rec.Seq]; ok {
+				report.Checkpoints++
+				delete(signed, rec.Seq)
+			}
+
+			report.Checked++
+			lastSeq, lastHash = rec.Seq, rec.Hash
+		}
+
+		if len(records) < verifyBatchSize {
+			break
+		}
+	}
+
+	for seq := range signed {
+		if report.BrokenAt == 0 || seq < report.BrokenAt {
+			report.BrokenAt = seq
+			report.Reason = "checkpoint refers to a missing record"
+		}
+	}
+
+	return report, nil
+}
+
+func (a *AuditTrail) check(rec domain.AuditRecord, prevSeq int64, prevHash string, signed map[int64]domain.AuditCheckpoint) string {
+	if rec.Seq != prevSeq+1 {
+		return fmt.Sprintf("expected seq %d, got %d", prevSeq+1, rec.Seq)
+	}
+
+	if rec.PrevHash != prevHash {
+		return "prev_hash does not match previous record"
+	}
+
+	if rec.ComputeHash() != rec.Hash {
+		return "record hash does not match its contents"
+	}
+
+	if cp, ok := signed[rec.Seq]; ok {
+		if cp.Hash != rec.Hash {
+			return "checkpoint hash does not match record"
+		}
+
+		if !hmac.Equal([]byte(cp.Signature), []byte(a.sign(cp.Seq, cp.Hash))) {
+			return "checkpoint signature is invalid"
+		}
+	}
+
+	return ""
+}
+
+func (a *AuditTrail) sign(seq int64, hash string) string {
+	mac := hmac.New(sha256.New, a.secret)
+	fmt.Fprintf(mac, "%d|%s", seq, hash)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
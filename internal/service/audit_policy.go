@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"math/rand"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	log "github.com/sirupsen/logrus"
+)
+
+// AuditRule decides what happens to events of one entity/action pair.
+// Empty Entity or Action match any value.
+type AuditRule struct {
+	Entity     string
+	Action     string
+	Enabled    bool
+	SampleRate float64
+	Fatal      bool
+}
+
+func (r AuditRule) matches(item audit.LogItem) bool {
+	return (r.Entity == "" || r.Entity == item.Entity) &&
+		(r.Action == "" || r.Action == item.Action)
+}
+
+// AuditPolicy is the single place where audit events are filtered, sampled
+// and where send failures are either propagated or swallowed. It wraps
+// another AuditClient and is handed to the services instead of it.
+type AuditPolicy struct {
+	next     AuditClient
+	defaults AuditRule
+	rules    []AuditRule
+	sample   func() float64
+}
+
+// NewAuditPolicy returns a policy that applies the first rule matching an
+// event, falling back to defaults.
+func NewAuditPolicy(next AuditClient, defaults AuditRule, rules []AuditRule) *AuditPolicy {
+	return &AuditPolicy{
+		next:     next,
+		defaults: defaults,
+		rules:    rules,
+		sample:   rand.Float64,
+	}
+}
+
+func (p *AuditPolicy) SendLogRequest(ctx context.Context, req audit.LogItem) error {
+	rule := p.ruleFor(req)
+	if !rule.Enabled {
+		return nil
+	}
+
+	if rule.SampleRate < 1 && p.sample() >= rule.SampleRate {
+		return nil
+	}
+
+	if err := p.next.SendLogRequest(ctx, req); err != nil {
+		if rule.Fatal {
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"entity":    req.Entity,
+			"action":    req.Action,
+			"entity_id": req.EntityID,
+			"error":     err,
+		}).Warn("audit event dropped")
+	}
+
+	return nil
+}
+
+func (p *AuditPolicy) ruleFor(item audit.LogItem) AuditRule {
+	for _, rule := range p.rules {
+		if rule.matches(item) {
+			return rule
+		}
+	}
+
+	return p.defaults
+}
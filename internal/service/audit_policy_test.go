@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+)
+
+func TestAuditPolicy(t *testing.T) {
+	errSend := errors.New("audit unavailable")
+
+	defaults := AuditRule{Enabled: true, SampleRate: 1}
+	rules := []AuditRule{
+		{Entity: audit.ENTITY_BOOK, Action: audit.ACTION_GET, Enabled: false},
+		{Entity: audit.ENTITY_BOOK, Action: audit.ACTION_UPDATE, Enabled: true, SampleRate: 0.25},
+		{Entity: audit.ENTITY_BOOK, Enabled: true, SampleRate: 1, Fatal: true},
+		{Action: audit.ACTION_DELETE, Enabled: true, SampleRate: 0},
+	}
+
+	tests := []struct {
+		name    string
+		entity  string
+		action  string
+		sample  float64
+		sendErr error
+		sent    bool
+		err     error
+	}{
+		{"disabled rule", audit.ENTITY_BOOK, audit.ACTION_GET, 0, nil, false, nil},
+		{"fraction sampled in", audit.ENTITY_BOOK, audit.ACTION_UPDATE, 0.1, nil, true, nil},
+		{"fraction sampled out", audit.ENTITY_BOOK, audit.ACTION_UPDATE, 0.25, nil, false, nil},
+		{"entity rule", audit.ENTITY_BOOK, audit.ACTION_CREATE, 0.99, nil, true, nil},
+		{"fatal rule returns the error", audit.ENTITY_BOOK, audit.ACTION_CREATE, 0, errSend, true, errSend},
+		{"action rule with rate 0", audit.ENTITY_USER, audit.ACTION_DELETE, 0, nil, false, nil},
+		{"defaults", audit.ENTITY_USER, audit.ACTION_CREATE, 0.99, nil, true, nil},
+		{"non-fatal rule swallows the error", audit.ENTITY_USER, audit.ACTION_CREATE, 0, errSend, true, nil},
+		{"non-fatal sampled rule swallows the error", audit.ENTITY_BOOK, audit.ACTION_UPDATE, 0, errSend, true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &recordingAudit{err: tt.sendErr}
+			policy := NewAuditPolicy(next, defaults, rules)
+			policy.sample = func() float64 { return tt.sample }
+
+			err := policy.SendLogRequest(context.Background(), audit.LogItem{Entity: tt.entity, Action: tt.action, EntityID: 1})
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Errorf("SendLogRequest = %v, want %v", err, tt.err)
+			}
+			if sent := len(next.sent) == 1; sent != tt.sent {
+				t.Errorf("sent = %v, want %v", sent, tt.sent)
+			}
+		})
+	}
+}
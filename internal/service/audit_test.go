@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+)
+
+// memoryAudit chains records in memory the way the database store does.
+type memoryAudit struct {
+	records     []domain.AuditRecord
+	checkpoints []domain.AuditCheckpoint
+}
+
+func (m *memoryAudit) Append(_ context.Context, rec domain.AuditRecord) (domain.AuditRecord, error) {
+	rec.Seq = int64(len(m.records)) + 1
+	if len(m.records) > 0 {
+		rec.PrevHash = m.records[len(m.records)-1].Hash
+	}
+	rec.Hash = rec.ComputeHash()
+
+	m.records = append(m.records, rec)
+	return rec, nil
+}
+
+func (m *memoryAudit) List(_ context.Context, afterSeq int64, limit int) ([]domain.AuditRecord, error) {
+	var out []domain.AuditRecord
+	for _, rec := range m.records {
+		if rec.Seq > afterSeq && len(out) < limit {
+			out = append(out, rec)
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryAudit) CreateCheckpoint(_ context.Context, cp domain.AuditCheckpoint) error {
+	m.checkpoints = append(m.checkpoints, cp)
+	return nil
+}
+
+func (m *memoryAudit) GetCheckpoints(context.Context) ([]domain.AuditCheckpoint, error) {
+	return m.checkpoints, nil
+}
+
+func (m *memoryAudit) Find(context.Context, domain.AuditFilter) ([]domain.AuditRecord, int64, error) {
+	return m.records, int64(len(m.records)), nil
+}
+
+type recordingAudit struct {
+	sent []audit.LogItem
+	err  error
+}
+
+func (r *recordingAudit) SendLogRequest(_ context.Context, req audit.LogItem) error {
+	r.sent = append(r.sent, req)
+	return r.err
+}
+
+// newTestTrail appends n book updates to a trail checkpointed every 3
+// records.
+func newTestTrail(t *testing.T, n int) (*AuditTrail, *memoryAudit) {
+	t.Helper()
+
+	repo := &memoryAudit{}
+	trail := NewAuditTrail(repo, &recordingAudit{}, []byte("secret"), 3)
+
+	for i := 0; i < n; i++ {
+		err := trail.SendLogRequest(context.Background(), audit.LogItem{
+			Entity:    audit.ENTITY_BOOK,
+			Action:    audit.ACTION_UPDATE,
+			EntityID:  int64(i + 1),
+			Timestamp: time.Date(2026, time.March, 1, 12, 0, i, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return trail, repo
+}
+
+func TestAuditTrailCheckpoints(t *testing.T) {
+	_, repo := newTestTrail(t, 7)
+
+	if len(repo.checkpoints) != 2 {
+		t.Fatalf("got %d checkpoints, want 2", len(repo.checkpoints))
+	}
+	for i, seq := range []int64{3, 6} {
+		cp := repo.checkpoints[i]
+		if cp.Seq != seq || cp.Hash != repo.records[seq-1].Hash || cp.Signature == "" {
+			t.Errorf("checkpoint %d = %+v, want seq %d with its record hash", i, cp, seq)
+		}
+	}
+}
+
+func TestAuditTrailVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(trail *AuditTrail, repo *memoryAudit)
+		brokenAt int64
+		reason   string
+	}{
+		{"intact", func(*AuditTrail, *memoryAudit) {}, 0, ""},
+		{"tampered record", func(_ *AuditTrail, repo *memoryAudit) {
+			repo.records[3].EntityID = 99
+		}, 4, "record hash does not match its contents"},
+		{"rehashed record breaks the link", func(_ *AuditTrail, repo *memoryAudit) {
+			repo.records[3].EntityID = 99
+			repo.records[3].Hash = repo.records[3].ComputeHash()
+		}, 5, "prev_hash does not match previous record"},
+		{"missing record", func(_ *AuditTrail, repo *memoryAudit) {
+			repo.records = append(repo.records[:1], repo.records[2:]...)
+		}, 3, "expected seq 2, got 3"},
+		{"checkpoint signed with another key", func(trail *AuditTrail, repo *memoryAudit) {
+			forger := NewAuditTrail(repo, &recordingAudit{}, []byte("forged"), 3)
+			repo.checkpoints[1].Signature = forger.sign(repo.checkpoints[1].Seq, repo.checkpoints[1].Hash)
+		}, 6, "checkpoint signature is invalid"},
+		{"checkpoint past the head", func(_ *AuditTrail, repo *memoryAudit) {
+			repo.records = repo.records[:5]
+		}, 6, "checkpoint refers to a missing record"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trail, repo := newTestTrail(t, 7)
+			tt.tamper(trail, repo)
+
+			report, err := trail.Verify(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if report.BrokenAt != tt.brokenAt || report.Reason != tt.reason {
+				t.Errorf("Verify = broken at %d (%q), want %d (%q)", report.BrokenAt, report.Reason, tt.brokenAt, tt.reason)
+			}
+			if tt.brokenAt == 0 && (report.Checked != 7 || report.Checkpoints != 2) {
+				t.Errorf("checked %d records and %d checkpoints, want 7 and 2", report.Checked, report.Checkpoints)
+			}
+		})
+	}
+}
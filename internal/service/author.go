@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuthorsRepository interface {
+	Create(ctx context.Context, author domain.Author) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Author, error)
+	GetByID(ctx context.Context, id int64) (domain.Author, error)
+	Update(ctx context.Context, id int64, author domain.Author) error
+	Delete(ctx context.Context, id int64) error
+	Merge(ctx context.Context, from, into int64) error
+}
+
+type Authors struct {
+	repo        AuthorsRepository
+	auditClient AuditClient
+}
+
+func NewAuthors(repo AuthorsRepository, auditClient AuditClient) *Authors {
+	return &Authors{
+		repo:        repo,
+		auditClient: auditClient,
+	}
+}
+
+func (a *Authors) Create(ctx context.Context, inp domain.AuthorInput) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Authors.Create")
+	defer func() { endSpan(span, err) }()
+
+	id, err := a.repo.Create(ctx, domain.Author{Name: inp.Name, Bio: inp.Bio})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, a.audit(ctx, audit.ACTION_CREATE, id)
+}
+
+func (a *Authors) GetAll(ctx context.Context) (_ []domain.Author, err error) {
+	ctx, span := tracer.Start(ctx, "Authors.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return a.repo.GetAll(ctx)
+}
+
+func (a *Authors) GetByID(ctx context.Context, id int64) (_ domain.Author, err error) {
+	ctx, span := tracer.Start(ctx, "Authors.GetByID", trace.WithAttributes(attribute.Int64("author.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return a.repo.GetByID(ctx, id)
+}
+
+func (a *Authors) Update(ctx context.Context, id int64, inp domain.AuthorInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Authors.Update", trace.WithAttributes(attribute.Int64("author.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := a.repo.Update(ctx, id, domain.Author{Name: inp.Name, Bio: inp.Bio}); err != nil {
+		return err
+	}
+
+	return a.audit(ctx, audit.ACTION_UPDATE, id)
+}
+
+func (a *Authors) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Authors.Delete", trace.WithAttributes(attribute.Int64("author.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := a.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return a.audit(ctx, audit.ACTION_DELETE, id)
+}
+
+// Merge folds the author from into the author into: books of both end up
+// linked to into, and from is deleted.
+func (a *Authors) Merge(ctx context.Context, from int64, inp domain.AuthorMergeInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Authors.Merge", trace.WithAttributes(attribute.Int64("author.id", from)))
+	defer func() { endSpan(span, err) }()
+
+	if from == inp.Into {
+		return domain.NewValidationError(domain.FieldError{Field: "into", Message: "must differ from the merged author"})
+	}
+
+	if err := a.repo.Merge(ctx, from, inp.Into); err != nil {
+		return err
+	}
+
+	return a.audit(ctx, ActionMerge, from)
+}
+
+// Duplicates groups authors whose names are likely spelling variants of
+// each other, as candidates for Merge.
+func (a *Authors) Duplicates(ctx context.Context) (_ [][]domain.Author, err error) {
+	ctx, span := tracer.Start(ctx, "Authors.Duplicates")
+	defer func() { endSpan(span, err) }()
+
+	authors, err := a.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]domain.Author)
+	keys := make([]string, 0)
+	for _, author := range authors {
+		key := domain.AuthorKey(author.Name)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], author)
+	}
+
+	duplicates := make([][]domain.Author, 0)
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			duplicates = append(duplicates, groups[key])
+		}
+	}
+
+	return duplicates, nil
+}
+
+func (a *Authors) audit(ctx context.Context, action string, id int64) error {
+	return a.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityAuthor,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
@@ -2,18 +2,43 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"lib/internal/domain"
 	"time"
 
 	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("lib/internal/service")
+
+// endSpan ends span, marking it failed with err if there is one.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 type BooksRepository interface {
 	Create(ctx context.Context, book domain.Book) (int64, error)
-	Update(ctx context.Context, id int64, inp domain.UpdateBook) error
-	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context) ([]domain.Book, error)
+	Update(ctx context.Context, id int64, version int, inp domain.UpdateBook) (int, error)
+	Delete(ctx context.Context, id int64, version int) error
+	GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error)
+	Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error
 	GetByID(ctx context.Context, id int64) (domain.Book, error)
+	GetByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetTrash(ctx context.Context) ([]domain.Book, error)
+	Restore(ctx context.Context, id int64) error
+	Purge(ctx context.Context, before time.Time) ([]int64, error)
+	RefreshRatingStats(ctx context.Context) error
+	GetRevisions(ctx context.Context, bookID int64) ([]domain.BookRevision, error)
+	GetRevision(ctx context.Context, bookID int64, version int) (domain.BookRevision, error)
 }
 
 type Books struct {
@@ -28,12 +53,11 @@ func NewBooks(repo BooksRepository, auditClient AuditClient) *Books {
 	}
 }
 
-func (b *Books) Create(ctx context.Context, book domain.Book) error {
-	if book.Publisher.IsZero() {
-		book.Publisher = time.Now()
-	}
+func (b *Books) Create(ctx context.Context, inp domain.CreateBookInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Books.Create")
+	defer func() { endSpan(span, err) }()
 
-	id, err := b.repo.Create(ctx, book)
+	id, err := b.repo.Create(ctx, inp.Book())
 	if err != nil {
 		return err
 	}
@@ -48,10 +72,15 @@ func (b *Books) Create(ctx context.Context, book domain.Book) error {
 	return err
 }
 
-func (b *Books) Update(ctx context.Context, id int64, inp domain.UpdateBook) error {
-	err := b.repo.Update(ctx, id, inp)
+// Update applies inp if the stored version still equals version (0 skips
+// the check) and returns the new version.
+func (b *Books) Update(ctx context.Context, id int64, version int, inp domain.UpdateBookInput) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Books.Update", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	newVersion, err := b.repo.Update(ctx, id, version, inp.UpdateBook())
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	err = b.auditClient.SendLogRequest(ctx, audit.LogItem{
@@ -61,11 +90,16 @@ func (b *Books) Update(ctx context.Context, id int64, inp domain.UpdateBook) err
 		Timestamp: time.Now(),
 	})
 
-	return err
+	return newVersion, err
 }
 
-func (b *Books) Delete(ctx context.Context, id int64) error {
-	err := b.repo.Delete(ctx, id)
+// Delete removes the book if the stored version still equals version
+// (0 skips the check).
+func (b *Books) Delete(ctx context.Context, id int64, version int) (err error) {
+	ctx, span := tracer.Start(ctx, "Books.Delete", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	err = b.repo.Delete(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -80,8 +114,11 @@ func (b *Books) Delete(ctx context.Context, id int64) error {
 	return err
 }
 
-func (b *Books) GetAll(ctx context.Context) ([]domain.Book, error) {
-	books, err := b.repo.GetAll(ctx)
+func (b *Books) GetAll(ctx context.Context, filter domain.BookFilter) (_ []domain.Book, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	books, err := b.repo.GetAll(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +137,28 @@ func (b *Books) GetAll(ctx context.Context) ([]domain.Book, error) {
 	return books, nil
 }
 
-func (b *Books) GetByID(ctx context.Context, id int64) (domain.Book, error) {
+// Export streams the books matching filter to fn, one at a time. The
+// export is audited as a whole, against no book in particular.
+func (b *Books) Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) (err error) {
+	ctx, span := tracer.Start(ctx, "Books.Export")
+	defer func() { endSpan(span, err) }()
+
+	if err := b.repo.Export(ctx, filter, fn); err != nil {
+		return err
+	}
+
+	return b.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    audit.ENTITY_BOOK,
+		Action:    ActionExport,
+		EntityID:  0,
+		Timestamp: time.Now(),
+	})
+}
+
+func (b *Books) GetByID(ctx context.Context, id int64) (_ domain.Book, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetByID", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
 	book, err := b.repo.GetByID(ctx, id)
 	if err != nil {
 		return domain.Book{}, err
@@ -119,3 +177,117 @@ func (b *Books) GetByID(ctx context.Context, id int64) (domain.Book, error) {
 
 	return book, nil
 }
+
+// GetByISBN looks up a book by ISBN-10 or ISBN-13.
+func (b *Books) GetByISBN(ctx context.Context, isbn string) (_ domain.Book, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetByISBN", trace.WithAttributes(attribute.String("book.isbn", isbn)))
+	defer func() { endSpan(span, err) }()
+
+	isbn13, err := domain.ParseISBN(isbn)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	book, err := b.repo.GetByISBN(ctx, isbn13)
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	err = b.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    audit.ENTITY_BOOK,
+		Action:    audit.ACTION_GET,
+		EntityID:  int64(book.ID),
+		Timestamp: time.Now(),
+	})
+
+	if err != nil {
+		return domain.Book{}, err
+	}
+
+	return book, nil
+}
+
+func (b *Books) GetTrash(ctx context.Context) (_ []domain.Book, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetTrash")
+	defer func() { endSpan(span, err) }()
+
+	return b.repo.GetTrash(ctx)
+}
+
+func (b *Books) Restore(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Books.Restore", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := b.repo.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	return b.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    audit.ENTITY_BOOK,
+		Action:    ActionRestore,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
+
+// PurgeTrash permanently deletes books that have been in the trash for
+// longer than retention and returns the IDs of those removed, even when
+// auditing them fails. Every removal is audited; failures are joined.
+func (b *Books) PurgeTrash(ctx context.Context, retention time.Duration) (_ []int64, err error) {
+	ctx, span := tracer.Start(ctx, "Books.PurgeTrash")
+	defer func() { endSpan(span, err) }()
+
+	ids, err := b.repo.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, id := range ids {
+		if err := b.auditClient.SendLogRequest(ctx, audit.LogItem{
+			Entity:    audit.ENTITY_BOOK,
+			Action:    ActionPurge,
+			EntityID:  id,
+			Timestamp: time.Now(),
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("audit purge of book %d: %w", id, err))
+		}
+	}
+
+	return ids, errors.Join(errs...)
+}
+
+// RecomputeRatings refreshes the rating aggregates shown on authors and
+// publishers.
+func (b *Books) RecomputeRatings(ctx context.Context) (err error) {
+	ctx, span := tracer.Start(ctx, "Books.RecomputeRatings")
+	defer func() { endSpan(span, err) }()
+
+	return b.repo.RefreshRatingStats(ctx)
+}
+
+func (b *Books) GetRevisions(ctx context.Context, id int64) (_ []domain.BookRevision, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetRevisions", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return b.repo.GetRevisions(ctx, id)
+}
+
+func (b *Books) GetRevision(ctx context.Context, id int64, revision int) (_ domain.BookRevision, err error) {
+	ctx, span := tracer.Start(ctx, "Books.GetRevision", trace.WithAttributes(attribute.Int64("book.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return b.repo.GetRevision(ctx, id, revision)
+}
+
+// Revert brings the book back to the state recorded in revision through the
+// regular Update path, so it is versioned, audited and recorded as a new
+// revision like any other edit. Books in the trash cannot be reverted.
+func (b *Books) Revert(ctx context.Context, id int64, revision, version int) (int, error) {
+	rev, err := b.GetRevision(ctx, id, revision)
+	if err != nil {
+		return 0, err
+	}
+
+	return b.Update(ctx, id, version, rev.UpdateInput())
+}
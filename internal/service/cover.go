@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"lib/internal/domain"
+	"lib/pkg/blob"
+	"lib/pkg/imaging"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxCoverPixels bounds the decoded size of a cover, so a small file
+// cannot expand into an image that exhausts memory.
+const maxCoverPixels = 25_000_000
+
+// thumbnailQuality is the JPEG quality of thumbnails of JPEG covers.
+const thumbnailQuality = 85
+
+// coverTypes are the sniffed content types accepted as covers.
+var coverTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type CoversRepository interface {
+	Replace(ctx context.Context, cover domain.Cover, write func() error) (domain.Cover, error)
+	GetByBookID(ctx context.Context, bookID int64) (domain.Cover, error)
+	Delete(ctx context.Context, bookID int64) error
+}
+
+// BlobStore keeps the image data of covers; the repository keeps what is
+// known about them.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Covers stores book cover images along with thumbnails in every size of
+// domain.CoverSizes.
+type Covers struct {
+	repo        CoversRepository
+	store       BlobStore
+	auditClient AuditClient
+}
+
+func NewCovers(repo CoversRepository, store BlobStore, auditClient AuditClient) *Covers {
+	return &Covers{
+		repo:        repo,
+		store:       store,
+		auditClient: auditClient,
+	}
+}
+
+// Put replaces the cover of a book with the image in data. The content type
+// is sniffed from the data rather than trusted from the client. Images are
+// only written once the book is known to exist, one Put per book at a
+// time. A Put that fails after writing images removes them all, as the
+// previous images may already be overwritten.
+func (c *Covers) Put(ctx context.Context, bookID int64, data []byte) (_ domain.Cover, err error) {
+	ctx, span := tracer.Start(ctx, "Covers.Put", trace.WithAttributes(attribute.Int64("book.id", bookID)))
+	defer func() { endSpan(span, err) }()
+
+	contentType := http.DetectContentType(data)
+	if !coverTypes[contentType] {
+		return domain.Cover{}, domain.ErrCoverType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.Cover{}, domain.ErrCoverType
+	}
+	if cfg.Width*cfg.Height > maxCoverPixels {
+		return domain.Cover{}, fmt.Errorf("%w: cover must not exceed %d pixels", domain.ErrTooLarge, maxCoverPixels)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return domain.Cover{}, domain.ErrCoverType
+	}
+
+	sum := sha256.Sum256(data)
+	cover := domain.Cover{
+		BookID:      bookID,
+		ContentType: contentType,
+		Width:       cfg.Width,
+		Height:      cfg.Height,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(sum[:]),
+	}
+
+	blobs := map[string][]byte{domain.CoverOriginal: data}
+	for size, px := range domain.CoverSizes {
+		thumb, err := encodeThumbnail(imaging.Fit(img, px), cover.MediaType(size))
+		if err != nil {
+			return domain.Cover{}, err
+		}
+		blobs[size] = thumb
+	}
+
+	written := false
+	cover, err = c.repo.Replace(ctx, cover, func() error {
+		written = true
+		for size, b := range blobs {
+			if err := c.store.Put(ctx, coverKey(bookID, size), bytes.NewReader(b), int64(len(b)), cover.MediaType(size)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if written {
+			c.removeBlobs(ctx, bookID)
+		}
+		return domain.Cover{}, err
+	}
+
+	return cover, c.audit(ctx, audit.ACTION_UPDATE, bookID)
+}
+
+func (c *Covers) Get(ctx context.Context, bookID int64) (_ domain.Cover, err error) {
+	ctx, span := tracer.Start(ctx, "Covers.Get", trace.WithAttributes(attribute.Int64("book.id", bookID)))
+	defer func() { endSpan(span, err) }()
+
+	return c.repo.GetByBookID(ctx, bookID)
+}
+
+// Open returns the image data of the cover of a book at size. The caller
+// must close it.
+func (c *Covers) Open(ctx context.Context, bookID int64, size string) (_ io.ReadCloser, err error) {
+	ctx, span := tracer.Start(ctx, "Covers.Open", trace.WithAttributes(attribute.Int64("book.id", bookID)))
+	defer func() { endSpan(span, err) }()
+
+	rc, err := c.store.Get(ctx, coverKey(bookID, size))
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, domain.ErrCoverNotFound
+	}
+
+	return rc, err
+}
+
+func (c *Covers) Delete(ctx context.Context, bookID int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Covers.Delete", trace.WithAttributes(attribute.Int64("book.id", bookID)))
+	defer func() { endSpan(span, err) }()
+
+	if err := c.repo.Delete(ctx, bookID); err != nil {
+		return err
+	}
+
+	c.removeBlobs(ctx, bookID)
+
+	return c.audit(ctx, audit.ACTION_DELETE, bookID)
+}
+
+// Remove deletes the images of books that no longer exist. Their cover
+// records go with the books themselves.
+func (c *Covers) Remove(ctx context.Context, bookIDs ...int64) {
+	ctx, span := tracer.Start(ctx, "Covers.Remove")
+	defer span.End()
+
+	for _, id := range bookIDs {
+		c.removeBlobs(ctx, id)
+	}
+}
+
+// removeBlobs deletes every size of a cover. Failures leave orphaned blobs
+// behind rather than failing the caller, and are only logged.
+func (c *Covers) removeBlobs(ctx context.Context, bookID int64) {
+	for _, size := range coverSizes() {
+		if err := c.store.Delete(ctx, coverKey(bookID, size)); err != nil {
+			log.WithFields(log.Fields{"book": bookID, "size": size}).Error(err)
+		}
+	}
+}
+
+func (c *Covers) audit(ctx context.Context, action string, bookID int64) error {
+	return c.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityCover,
+		Action:    action,
+		EntityID:  bookID,
+		Timestamp: time.Now(),
+	})
+}
+
+func coverSizes() []string {
+	sizes := []string{domain.CoverOriginal}
+	for size := range domain.CoverSizes {
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+func coverKey(bookID int64, size string) string {
+	return "covers/" + strconv.FormatInt(bookID, 10) + "/" + size
+}
+
+func encodeThumbnail(img image.Image, mediaType string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var err error
+	if mediaType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+
+	return buf.Bytes(), err
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"lib/internal/domain"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type HoldsRepository interface {
+	Place(ctx context.Context, userID, bookID int64, policy domain.LoanPolicy) (domain.Hold, []domain.Hold, error)
+	Cancel(ctx context.Context, id, userID int64, policy domain.LoanPolicy) (domain.Hold, []domain.Hold, error)
+	Expire(ctx context.Context, policy domain.LoanPolicy) ([]domain.Hold, []domain.Hold, error)
+	GetByID(ctx context.Context, id int64) (domain.Hold, error)
+	GetAll(ctx context.Context, filter domain.HoldFilter) ([]domain.Hold, error)
+}
+
+// Notifier delivers notifications to readers.
+type Notifier interface {
+	Notify(ctx context.Context, n domain.Notification) error
+}
+
+// Holds manages the hold queues. Readers are notified whenever a copy is
+// set aside for them and when their hold expires unclaimed.
+type Holds struct {
+	repo        HoldsRepository
+	auditClient AuditClient
+	notifier    Notifier
+	policy      domain.LoanPolicy
+}
+
+func NewHolds(repo HoldsRepository, auditClient AuditClient, notifier Notifier, policy domain.LoanPolicy) *Holds {
+	return &Holds{
+		repo:        repo,
+		auditClient: auditClient,
+		notifier:    notifier,
+		policy:      policy,
+	}
+}
+
+// Place queues userID for a copy of a title.
+func (h *Holds) Place(ctx context.Context, userID int64, inp domain.HoldInput) (_ domain.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "Holds.Place", trace.WithAttributes(attribute.Int64("book.id", inp.BookID)))
+	defer func() { endSpan(span, err) }()
+
+	hold, ready, err := h.repo.Place(ctx, userID, inp.BookID, h.policy)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	notifyReady(ctx, h.notifier, ready)
+
+	return hold, h.audit(ctx, audit.ACTION_CREATE, hold.ID)
+}
+
+// Cancel cancels any active hold.
+func (h *Holds) Cancel(ctx context.Context, id int64) (domain.Hold, error) {
+	return h.cancel(ctx, id, 0)
+}
+
+// CancelOwn cancels an active hold of userID. Holds of other users are
+// reported as not found.
+func (h *Holds) CancelOwn(ctx context.Context, userID, id int64) (domain.Hold, error) {
+	return h.cancel(ctx, id, userID)
+}
+
+func (h *Holds) cancel(ctx context.Context, id, userID int64) (_ domain.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "Holds.Cancel", trace.WithAttributes(attribute.Int64("hold.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	hold, ready, err := h.repo.Cancel(ctx, id, userID, h.policy)
+	if err != nil {
+		return domain.Hold{}, err
+	}
+
+	notifyReady(ctx, h.notifier, ready)
+
+	return hold, h.audit(ctx, ActionCancel, id)
+}
+
+// ExpireReady expires the ready holds that were not picked up in time and
+// passes their copies on. It returns the number of expired holds.
+func (h *Holds) ExpireReady(ctx context.Context) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Holds.ExpireReady")
+	defer func() { endSpan(span, err) }()
+
+	expired, ready, err := h.repo.Expire(ctx, h.policy)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, hold := range expired {
+		notify(ctx, h.notifier, domain.Notification{
+			Kind:    domain.NotificationHoldExpired,
+			UserID:  hold.UserID,
+			Subject: fmt.Sprintf("Your hold on %q has expired", hold.Title),
+			Body:    "The copy set aside for you was not picked up in time and has gone to the next reader.",
+			HoldID:  hold.ID,
+		})
+
+		if err := h.audit(ctx, ActionExpire, hold.ID); err != nil {
+			return len(expired), err
+		}
+	}
+
+	notifyReady(ctx, h.notifier, ready)
+
+	return len(expired), nil
+}
+
+func (h *Holds) GetByID(ctx context.Context, id int64) (_ domain.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "Holds.GetByID", trace.WithAttributes(attribute.Int64("hold.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return h.repo.GetByID(ctx, id)
+}
+
+func (h *Holds) GetAll(ctx context.Context, filter domain.HoldFilter) (_ []domain.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "Holds.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return h.repo.GetAll(ctx, filter)
+}
+
+func (h *Holds) audit(ctx context.Context, action string, id int64) error {
+	return h.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityHold,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
+
+// notifyReady tells the holders of newly ready holds where to pick up
+// their copy.
+func notifyReady(ctx context.Context, notifier Notifier, holds []domain.Hold) {
+	for _, hold := range holds {
+		body := fmt.Sprintf("Copy %s is waiting for you at the desk.", hold.Barcode)
+		if hold.ExpiresAt != nil {
+			body = fmt.Sprintf("Copy %s is waiting for you at the desk until %s.", hold.Barcode, hold.ExpiresAt.Format(time.RFC1123))
+		}
+
+		notify(ctx, notifier, domain.Notification{
+			Kind:    domain.NotificationHoldReady,
+			UserID:  hold.UserID,
+			Subject: fmt.Sprintf("%q is ready for pickup", hold.Title),
+			Body:    body,
+			HoldID:  hold.ID,
+		})
+	}
+}
+
+// notify sends a notification on a best-effort basis. The circulation
+// change it reports is already committed, so failures are only logged.
+func notify(ctx context.Context, notifier Notifier, n domain.Notification) {
+	if err := notifier.Notify(ctx, n); err != nil {
+		log.WithFields(log.Fields{"kind": n.Kind, "user_id": n.UserID, "hold_id": n.HoldID}).Error(err)
+	}
+}
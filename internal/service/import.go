@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"lib/internal/bookio"
+	"lib/internal/domain"
+	"sync"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type BooksImporter interface {
+	Import(ctx context.Context, books []domain.CreateBookInput, dryRun bool) ([]domain.ImportResult, error)
+}
+
+type ImportsRepository interface {
+	Create(ctx context.Context, job domain.ImportJob) (int64, error)
+	SetStatus(ctx context.Context, id int64, status string) error
+	Finish(ctx context.Context, id int64, status string, report *domain.ImportReport, jobErr string) error
+	FailUnfinished(ctx context.Context, jobErr string) (int64, error)
+	GetByID(ctx context.Context, id int64) (domain.ImportJob, error)
+}
+
+// Imports loads books in bulk. Rows are validated one by one and written
+// in batches, one transaction per batch. Every import is tracked as a job
+// and, unless it is a dry run, audited once as a whole.
+type Imports struct {
+	books       BooksImporter
+	repo        ImportsRepository
+	auditClient AuditClient
+	batchSize   int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewImports(books BooksImporter, repo ImportsRepository, auditClient AuditClient, batchSize int) *Imports {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Imports{
+		books:       books,
+		repo:        repo,
+		auditClient: auditClient,
+		batchSize:   batchSize,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Run imports src before returning the finished job.
+func (i *Imports) Run(ctx context.Context, format string, src bookio.Reader, dryRun bool) (_ domain.ImportJob, err error) {
+	ctx, span := tracer.Start(ctx, "Imports.Run", trace.WithAttributes(attribute.Bool("import.dry_run", dryRun)))
+	defer func() { endSpan(span, err) }()
+
+	id, err := i.create(ctx, format, dryRun, domain.ImportRunning)
+	if err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	if err := i.run(ctx, id, src, dryRun); err != nil {
+		return domain.ImportJob{}, err
+	}
+
+	return i.repo.GetByID(ctx, id)
+}
+
+// Start imports src in the background and returns the pending job. release
+// is called once src is no longer needed.
+func (i *Imports) Start(ctx context.Context, format string, src bookio.Reader, release func(), dryRun bool) (_ domain.ImportJob, err error) {
+	ctx, span := tracer.Start(ctx, "Imports.Start", trace.WithAttributes(attribute.Bool("import.dry_run", dryRun)))
+	defer func() { endSpan(span, err) }()
+
+	id, err := i.create(ctx, format, dryRun, domain.ImportPending)
+	if err != nil {
+		release()
+		return domain.ImportJob{}, err
+	}
+
+	// The job outlives the request: it runs under the service context, as
+	// the same user, in a trace linked to the request.
+	jobCtx := i.ctx
+	if userID, ok := domain.UserIDFromContext(ctx); ok {
+		jobCtx = domain.WithUserID(jobCtx, userID)
+	}
+	link := trace.LinkFromContext(ctx)
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+		defer release()
+
+		ctx, span := tracer.Start(jobCtx, "Imports.Job", trace.WithLinks(link), trace.WithAttributes(attribute.Int64("import.id", id)))
+		var err error
+		defer func() { endSpan(span, err) }()
+
+		if err = i.repo.SetStatus(ctx, id, domain.ImportRunning); err != nil {
+			log.WithField("import", id).Error(err)
+			return
+		}
+
+		if err = i.run(ctx, id, src, dryRun); err != nil {
+			log.WithField("import", id).Error(err)
+		}
+	}()
+
+	return i.repo.GetByID(ctx, id)
+}
+
+func (i *Imports) GetByID(ctx context.Context, id int64) (_ domain.ImportJob, err error) {
+	ctx, span := tracer.Start(ctx, "Imports.GetByID", trace.WithAttributes(attribute.Int64("import.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return i.repo.GetByID(ctx, id)
+}
+
+// Recover fails the jobs left pending or running by a previous process.
+// Background imports live in the process that accepted them, so it must be
+// called at startup, before any import is started.
+func (i *Imports) Recover(ctx context.Context) error {
+	n, err := i.repo.FailUnfinished(ctx, "interrupted by a restart")
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		log.WithField("imports", n).Warn("failed imports interrupted by a restart")
+	}
+
+	return nil
+}
+
+// Shutdown waits for background imports to finish. When ctx expires first,
+// the remaining imports are cancelled and waited for while they record
+// themselves as failed.
+func (i *Imports) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		i.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		i.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (i *Imports) create(ctx context.Context, format string, dryRun bool, status string) (int64, error) {
+	actorID, _ := domain.UserIDFromContext(ctx)
+
+	return i.repo.Create(ctx, domain.ImportJob{
+		Status:  status,
+		Format:  format,
+		DryRun:  dryRun,
+		ActorID: actorID,
+	})
+}
+
+// run imports src and records the outcome on job id. Batches committed
+// before a failure stay in the catalog, so they are audited as well.
+func (i *Imports) run(ctx context.Context, id int64, src bookio.Reader, dryRun bool) error {
+	report := &domain.ImportReport{Errors: make([]domain.ImportRowError, 0)}
+	err := i.load(ctx, src, dryRun, report)
+
+	// Record the outcome even when ctx was cancelled mid-import.
+	finishCtx := context.WithoutCancel(ctx)
+	status, jobErr := domain.ImportSucceeded, ""
+	if err != nil {
+		status, jobErr = domain.ImportFailed, err.Error()
+	}
+
+	if ferr := i.repo.Finish(finishCtx, id, status, report, jobErr); ferr != nil {
+		log.WithField("import", id).Error(ferr)
+		if err == nil {
+			err = ferr
+		}
+	}
+
+	if !dryRun && report.Created+report.Updated > 0 {
+		aerr := i.auditClient.SendLogRequest(finishCtx, audit.LogItem{
+			Entity:    EntityImport,
+			Action:    audit.ACTION_CREATE,
+			EntityID:  id,
+			Timestamp: time.Now(),
+		})
+		if err == nil {
+			err = aerr
+		}
+	}
+
+	return err
+}
+
+// load reads src to the end, writing valid rows in batches. report covers
+// the rows processed so far even when an error stops the import. A panic,
+// say on a malformed upload, fails the import rather than the server.
+func (i *Imports) load(ctx context.Context, src bookio.Reader, dryRun bool, report *domain.ImportReport) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("panic", r).Error("import aborted")
+			err = fmt.Errorf("import aborted: %v", r)
+		}
+	}()
+
+	batch := make([]domain.CreateBookInput, 0, i.batchSize)
+	rows := make([]int, 0, i.batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := i.books.Import(ctx, batch, dryRun)
+		if err != nil {
+			return err
+		}
+
+		for k, res := range results {
+			switch {
+			case res.Err != nil:
+				report.Reject(rows[k], res.Err)
+			case res.Created:
+				report.Created++
+			default:
+				report.Updated++
+			}
+		}
+
+		batch, rows = batch[:0], rows[:0]
+		return nil
+	}
+
+	for row := 1; ; row++ {
+		inp, err := src.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err == nil {
+			inp.Normalize()
+			err = inp.Validate()
+		}
+
+		if err != nil {
+			if !errors.Is(err, domain.ErrValidation) {
+				return err
+			}
+
+			report.Total++
+			report.Reject(row, err)
+			continue
+		}
+
+		report.Total++
+		batch = append(batch, inp)
+		rows = append(rows, row)
+
+		if len(batch) >= i.batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
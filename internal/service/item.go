@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ItemsRepository interface {
+	Create(ctx context.Context, item domain.Item, policy domain.LoanPolicy) (int64, []domain.Hold, error)
+	GetAll(ctx context.Context, filter domain.ItemFilter) ([]domain.Item, error)
+	GetByID(ctx context.Context, id int64) (domain.Item, error)
+	GetByBarcode(ctx context.Context, code string) (domain.Item, error)
+	Update(ctx context.Context, id int64, item domain.Item, policy domain.LoanPolicy) ([]domain.Hold, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// Items manages the physical copies of books. Copies that become available
+// go to the next hold of their title, whose holder is notified.
+type Items struct {
+	repo        ItemsRepository
+	auditClient AuditClient
+	notifier    Notifier
+	policy      domain.LoanPolicy
+}
+
+func NewItems(repo ItemsRepository, auditClient AuditClient, notifier Notifier, policy domain.LoanPolicy) *Items {
+	return &Items{
+		repo:        repo,
+		auditClient: auditClient,
+		notifier:    notifier,
+		policy:      policy,
+	}
+}
+
+func (i *Items) Create(ctx context.Context, inp domain.ItemInput) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Items.Create")
+	defer func() { endSpan(span, err) }()
+
+	id, ready, err := i.repo.Create(ctx, inp.Item(), i.policy)
+	if err != nil {
+		return 0, err
+	}
+
+	notifyReady(ctx, i.notifier, ready)
+
+	return id, i.audit(ctx, audit.ACTION_CREATE, id)
+}
+
+func (i *Items) GetAll(ctx context.Context, filter domain.ItemFilter) (_ []domain.Item, err error) {
+	ctx, span := tracer.Start(ctx, "Items.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return i.repo.GetAll(ctx, filter)
+}
+
+func (i *Items) GetByID(ctx context.Context, id int64) (_ domain.Item, err error) {
+	ctx, span := tracer.Start(ctx, "Items.GetByID", trace.WithAttributes(attribute.Int64("item.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return i.repo.GetByID(ctx, id)
+}
+
+// GetByBarcode looks up an item by a scanned EAN-13 or Code 128 barcode.
+func (i *Items) GetByBarcode(ctx context.Context, code string) (_ domain.Item, err error) {
+	ctx, span := tracer.Start(ctx, "Items.GetByBarcode", trace.WithAttributes(attribute.String("item.barcode", code)))
+	defer func() { endSpan(span, err) }()
+
+	if _, err := domain.ParseBarcode(code); err != nil {
+		return domain.Item{}, err
+	}
+
+	return i.repo.GetByBarcode(ctx, code)
+}
+
+func (i *Items) Update(ctx context.Context, id int64, inp domain.ItemInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Items.Update", trace.WithAttributes(attribute.Int64("item.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	ready, err := i.repo.Update(ctx, id, inp.Item(), i.policy)
+	if err != nil {
+		return err
+	}
+
+	notifyReady(ctx, i.notifier, ready)
+
+	return i.audit(ctx, audit.ACTION_UPDATE, id)
+}
+
+func (i *Items) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Items.Delete", trace.WithAttributes(attribute.Int64("item.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := i.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return i.audit(ctx, audit.ACTION_DELETE, id)
+}
+
+func (i *Items) audit(ctx context.Context, action string, id int64) error {
+	return i.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityItem,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
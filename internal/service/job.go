@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+)
+
+type JobRunsRepository interface {
+	GetAll(ctx context.Context, filter domain.JobRunFilter) ([]domain.JobRun, error)
+}
+
+// JobRuns reports the history of scheduled job runs.
+type JobRuns struct {
+	repo JobRunsRepository
+}
+
+func NewJobRuns(repo JobRunsRepository) *JobRuns {
+	return &JobRuns{
+		repo: repo,
+	}
+}
+
+func (j *JobRuns) GetAll(ctx context.Context, filter domain.JobRunFilter) (_ []domain.JobRun, err error) {
+	ctx, span := tracer.Start(ctx, "JobRuns.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return j.repo.GetAll(ctx, filter)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"lib/pkg/money"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type LedgerRepository interface {
+	Accrue(ctx context.Context, policy domain.FinePolicy) (int64, error)
+	Credit(ctx context.Context, userID int64, kind string, amount money.Amount, note string, recordedBy int64) (domain.LedgerEntry, error)
+	GetAccount(ctx context.Context, userID int64) (domain.Account, error)
+}
+
+// Ledger keeps the patron accounts: it accrues overdue fines under the
+// fine policy and records the payments and waivers librarians take.
+type Ledger struct {
+	repo        LedgerRepository
+	auditClient AuditClient
+	policy      domain.FinePolicy
+}
+
+func NewLedger(repo LedgerRepository, auditClient AuditClient, policy domain.FinePolicy) *Ledger {
+	return &Ledger{
+		repo:        repo,
+		auditClient: auditClient,
+		policy:      policy,
+	}
+}
+
+// Accrue charges the overdue fines due so far and returns the number of
+// new charges. It is safe to run any number of times a day.
+func (l *Ledger) Accrue(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Ledger.Accrue")
+	defer func() { endSpan(span, err) }()
+
+	return l.repo.Accrue(ctx, l.policy)
+}
+
+// Pay records a payment by userID, taken by the current user.
+func (l *Ledger) Pay(ctx context.Context, userID int64, inp domain.CreditInput) (domain.LedgerEntry, error) {
+	return l.credit(ctx, userID, domain.LedgerPayment, ActionPayment, inp)
+}
+
+// Waive forgives part of the balance of userID on behalf of the current
+// user.
+func (l *Ledger) Waive(ctx context.Context, userID int64, inp domain.CreditInput) (domain.LedgerEntry, error) {
+	return l.credit(ctx, userID, domain.LedgerWaiver, ActionWaive, inp)
+}
+
+func (l *Ledger) credit(ctx context.Context, userID int64, kind, action string, inp domain.CreditInput) (_ domain.LedgerEntry, err error) {
+	ctx, span := tracer.Start(ctx, "Ledger.Credit", trace.WithAttributes(
+		attribute.Int64("user.id", userID), attribute.String("ledger.kind", kind)))
+	defer func() { endSpan(span, err) }()
+
+	recordedBy, _ := domain.UserIDFromContext(ctx)
+
+	entry, err := l.repo.Credit(ctx, userID, kind, inp.Amount, inp.Note, recordedBy)
+	if err != nil {
+		return domain.LedgerEntry{}, err
+	}
+
+	return entry, l.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityLedger,
+		Action:    action,
+		EntityID:  entry.ID,
+		Timestamp: time.Now(),
+	})
+}
+
+func (l *Ledger) GetAccount(ctx context.Context, userID int64) (_ domain.Account, err error) {
+	ctx, span := tracer.Start(ctx, "Ledger.GetAccount", trace.WithAttributes(attribute.Int64("user.id", userID)))
+	defer func() { endSpan(span, err) }()
+
+	return l.repo.GetAccount(ctx, userID)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type LoansRepository interface {
+	Checkout(ctx context.Context, itemID int64, barcode string, userID int64, policy domain.LoanPolicy) (domain.Loan, error)
+	Return(ctx context.Context, id int64, policy domain.LoanPolicy) (domain.Loan, []domain.Hold, error)
+	Renew(ctx context.Context, id, userID int64, policy domain.LoanPolicy) (domain.Loan, error)
+	GetByID(ctx context.Context, id int64) (domain.Loan, error)
+	GetAll(ctx context.Context, filter domain.LoanFilter) ([]domain.Loan, error)
+}
+
+// Loans runs circulation: lending items, taking them back and renewing
+// loans under the configured loan policy. Returned copies go to the next
+// hold on their title, whose reader is notified.
+type Loans struct {
+	repo        LoansRepository
+	auditClient AuditClient
+	notifier    Notifier
+	policy      domain.LoanPolicy
+}
+
+func NewLoans(repo LoansRepository, auditClient AuditClient, notifier Notifier, policy domain.LoanPolicy) *Loans {
+	return &Loans{
+		repo:        repo,
+		auditClient: auditClient,
+		notifier:    notifier,
+		policy:      policy,
+	}
+}
+
+func (l *Loans) Checkout(ctx context.Context, inp domain.CheckoutInput) (_ domain.Loan, err error) {
+	ctx, span := tracer.Start(ctx, "Loans.Checkout", trace.WithAttributes(attribute.Int64("user.id", inp.UserID)))
+	defer func() { endSpan(span, err) }()
+
+	loan, err := l.repo.Checkout(ctx, inp.ItemID, inp.Barcode, inp.UserID, l.policy)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, l.audit(ctx, ActionCheckout, loan.ID)
+}
+
+func (l *Loans) Return(ctx context.Context, id int64) (_ domain.Loan, err error) {
+	ctx, span := tracer.Start(ctx, "Loans.Return", trace.WithAttributes(attribute.Int64("loan.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	loan, ready, err := l.repo.Return(ctx, id, l.policy)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	notifyReady(ctx, l.notifier, ready)
+
+	return loan, l.audit(ctx, ActionReturn, id)
+}
+
+// Renew extends any open loan.
+func (l *Loans) Renew(ctx context.Context, id int64) (domain.Loan, error) {
+	return l.renew(ctx, id, 0)
+}
+
+// RenewOwn extends an open loan of userID. Loans of other users are
+// reported as not found.
+func (l *Loans) RenewOwn(ctx context.Context, userID, id int64) (domain.Loan, error) {
+	return l.renew(ctx, id, userID)
+}
+
+func (l *Loans) renew(ctx context.Context, id, userID int64) (_ domain.Loan, err error) {
+	ctx, span := tracer.Start(ctx, "Loans.Renew", trace.WithAttributes(attribute.Int64("loan.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	loan, err := l.repo.Renew(ctx, id, userID, l.policy)
+	if err != nil {
+		return domain.Loan{}, err
+	}
+
+	return loan, l.audit(ctx, ActionRenew, id)
+}
+
+func (l *Loans) GetByID(ctx context.Context, id int64) (_ domain.Loan, err error) {
+	ctx, span := tracer.Start(ctx, "Loans.GetByID", trace.WithAttributes(attribute.Int64("loan.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return l.repo.GetByID(ctx, id)
+}
+
+func (l *Loans) GetAll(ctx context.Context, filter domain.LoanFilter) (_ []domain.Loan, err error) {
+	ctx, span := tracer.Start(ctx, "Loans.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return l.repo.GetAll(ctx, filter)
+}
+
+// Overdue lists the open loans past their due date.
+func (l *Loans) Overdue(ctx context.Context) ([]domain.Loan, error) {
+	return l.GetAll(ctx, domain.LoanFilter{Overdue: true})
+}
+
+func (l *Loans) audit(ctx context.Context, action string, id int64) error {
+	return l.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityLoan,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"lib/internal/domain"
+	"time"
+
+	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type PublishersRepository interface {
+	Create(ctx context.Context, publisher domain.Publisher) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Publisher, error)
+	GetByID(ctx context.Context, id int64) (domain.Publisher, error)
+	Update(ctx context.Context, id int64, publisher domain.Publisher) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type Publishers struct {
+	repo        PublishersRepository
+	auditClient AuditClient
+}
+
+func NewPublishers(repo PublishersRepository, auditClient AuditClient) *Publishers {
+	return &Publishers{
+		repo:        repo,
+		auditClient: auditClient,
+	}
+}
+
+func (p *Publishers) Create(ctx context.Context, inp domain.PublisherInput) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Publishers.Create")
+	defer func() { endSpan(span, err) }()
+
+	id, err := p.repo.Create(ctx, inp.Publisher())
+	if err != nil {
+		return 0, err
+	}
+
+	return id, p.audit(ctx, audit.ACTION_CREATE, id)
+}
+
+func (p *Publishers) GetAll(ctx context.Context) (_ []domain.Publisher, err error) {
+	ctx, span := tracer.Start(ctx, "Publishers.GetAll")
+	defer func() { endSpan(span, err) }()
+
+	return p.repo.GetAll(ctx)
+}
+
+func (p *Publishers) GetByID(ctx context.Context, id int64) (_ domain.Publisher, err error) {
+	ctx, span := tracer.Start(ctx, "Publishers.GetByID", trace.WithAttributes(attribute.Int64("publisher.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	return p.repo.GetByID(ctx, id)
+}
+
+func (p *Publishers) Update(ctx context.Context, id int64, inp domain.PublisherInput) (err error) {
+	ctx, span := tracer.Start(ctx, "Publishers.Update", trace.WithAttributes(attribute.Int64("publisher.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := p.repo.Update(ctx, id, inp.Publisher()); err != nil {
+		return err
+	}
+
+	return p.audit(ctx, audit.ACTION_UPDATE, id)
+}
+
+func (p *Publishers) Delete(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "Publishers.Delete", trace.WithAttributes(attribute.Int64("publisher.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := p.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return p.audit(ctx, audit.ACTION_DELETE, id)
+}
+
+func (p *Publishers) audit(ctx context.Context, action string, id int64) error {
+	return p.auditClient.SendLogRequest(ctx, audit.LogItem{
+		Entity:    EntityPublisher,
+		Action:    action,
+		EntityID:  id,
+		Timestamp: time.Now(),
+	})
+}
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
 	"lib/internal/domain"
@@ -21,11 +20,13 @@ type PasswordHasher interface {
 type UsersRepository interface {
 	Create(ctx context.Context, user domain.User) error
 	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
+	GetByID(ctx context.Context, id int64) (domain.User, error)
 }
 
 type SessionRepository interface {
 	Create(ctx context.Context, token domain.RefreshSession) error
 	Get(ctx context.Context, token string) (domain.RefreshSession, error)
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
 }
 
 type Users struct {
@@ -90,11 +91,12 @@ func (s *Users) SignIn(ctx context.Context, inp domain.SignInInput) (string, str
 		return "", "", err
 	}
 
+	// An unknown email and a wrong password look the same to the client.
 	user, err := s.repo.GetByCredentials(ctx, inp.Email, password)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return "", "", domain.ErrInvalidCredentials
+	}
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", "", domain.ErrUserNotFound
-		}
 		return "", "", err
 	}
 
@@ -149,6 +151,10 @@ func (s *Users) ParseToken(ctx context.Context, token string) (int64, error) {
 	return int64(id), nil
 }
 
+func (s *Users) GetByID(ctx context.Context, id int64) (domain.User, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
 func (s *Users) generateTokens(ctx context.Context, userId int64) (string, string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
 		Subject:   strconv.Itoa(int(userId)),
@@ -202,3 +208,12 @@ func (s *Users) RefreshToken(ctx context.Context, refreshToken string) (string,
 
 	return s.generateTokens(ctx, session.UserID)
 }
+
+// PurgeExpiredSessions deletes the refresh sessions that have expired and
+// returns how many were removed.
+func (s *Users) PurgeExpiredSessions(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "Users.PurgeExpiredSessions")
+	defer func() { endSpan(span, err) }()
+
+	return s.sessionRepo.DeleteExpired(ctx, time.Now())
+}
@@ -2,10 +2,13 @@ package grpc_client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/f0xg0sasha/audit_logger/pkg/domain/audit"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -19,7 +22,10 @@ func NewClient(port int) (*Client, error) {
 
 	addr := fmt.Sprintf(":%d", port)
 
-	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	conn, err := grpc.Dial(addr,
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -54,3 +60,26 @@ func (c *Client) SendLogRequest(ctx context.Context, req audit.LogItem) error {
 
 	return err
 }
+
+func (c *Client) Name() string {
+	return "audit"
+}
+
+// Check waits, within ctx, for the audit connection to become ready.
+func (c *Client) Check(ctx context.Context) error {
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return errors.New("connection is closed")
+		case connectivity.Idle:
+			c.conn.Connect()
+		}
+
+		if !c.conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("connection state %s: %w", state, ctx.Err())
+		}
+	}
+}
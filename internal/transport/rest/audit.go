@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"lib/internal/domain"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 1000
+	// maxAuditExportLimit caps a CSV export, which defaults to it. Larger
+	// exports are paged with offset, using the X-Total-Count header.
+	maxAuditExportLimit = 50000
+)
+
+func (h *Handler) getAuditRecords(w http.ResponseWriter, r *http.Request) {
+	csvExport := r.URL.Query().Get("format") == "csv"
+
+	defaultLimit, maxLimit := defaultAuditLimit, maxAuditLimit
+	if csvExport {
+		defaultLimit, maxLimit = maxAuditExportLimit, maxAuditExportLimit
+	}
+
+	filter, err := auditFilterFromQuery(r.URL.Query(), defaultLimit, maxLimit)
+	if err != nil {
+		writeError(w, r, "getAuditRecords", err)
+		return
+	}
+
+	records, total, err := h.auditService.Find(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "getAuditRecords", err)
+		return
+	}
+
+	if csvExport {
+		w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+		writeAuditCSV(w, records)
+		return
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"records": records,
+		"total":   total,
+		"limit":   filter.Limit,
+		"offset":  filter.Offset,
+	})
+	if err != nil {
+		writeError(w, r, "getAuditRecords", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func (h *Handler) getBookHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	records, err := h.auditService.BookHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "getBookHistory", err)
+		return
+	}
+
+	response, err := json.Marshal(records)
+	if err != nil {
+		writeError(w, r, "getBookHistory", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func writeAuditCSV(w http.ResponseWriter, records []domain.AuditRecord) {
+	w.Header().Add("Content-Type", "text/csv")
+	w.Header().Add("Content-Disposition", `attachment; filename="audit.csv"`)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"seq", "timestamp", "entity", "entity_id", "action", "actor_id", "prev_hash", "hash"})
+	for _, rec := range records {
+		cw.Write([]string{
+			strconv.FormatInt(rec.Seq, 10),
+			rec.Timestamp.UTC().Format(time.RFC3339Nano),
+			rec.Entity,
+			strconv.FormatInt(rec.EntityID, 10),
+			rec.Action,
+			strconv.FormatInt(rec.ActorID, 10),
+			rec.PrevHash,
+			rec.Hash,
+		})
+	}
+	cw.Flush()
+
+	if err := cw.Error(); err != nil {
+		logError("writeAuditCSV", err)
+	}
+}
+
+func auditFilterFromQuery(q url.Values, defaultLimit, maxLimit int) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		Entity: strings.ToUpper(q.Get("entity")),
+		Action: strings.ToUpper(q.Get("action")),
+		Limit:  defaultLimit,
+	}
+
+	var err error
+	if filter.EntityID, err = optionalInt(q, "entity_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.ActorID, err = optionalInt(q, "actor"); err != nil {
+		return filter, err
+	}
+
+	if filter.From, err = optionalTime(q, "from"); err != nil {
+		return filter, err
+	}
+
+	if filter.To, err = optionalTime(q, "to"); err != nil {
+		return filter, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, invalidParam("limit", "must be a positive integer")
+		}
+
+		if filter.Limit > maxLimit {
+			filter.Limit = maxLimit
+		}
+	}
+
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, invalidParam("offset", "must be a non-negative integer")
+		}
+	}
+
+	return filter, nil
+}
+
+func optionalInt(q url.Values, key string) (*int64, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, invalidParam(key, "must be an integer")
+	}
+
+	return &i, nil
+}
+
+func optionalTime(q url.Values, key string) (*time.Time, error) {
+	v := q.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, invalidParam(key, "must be an RFC 3339 timestamp")
+	}
+
+	return &t, nil
+}
+
+func invalidParam(name, msg string) error {
+	return domain.NewValidationError(domain.FieldError{Field: name, Message: msg})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"lib/internal/domain"
+	"lib/internal/metrics"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
@@ -14,27 +15,23 @@ import (
 func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
 	reqBytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logError("signUp", err)
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "body", err)
 		return
 	}
 
 	var inp domain.SignUpInput
 	if err := json.Unmarshal(reqBytes, &inp); err != nil {
-		logError("signUp", err)
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "body", err)
 		return
 	}
 
 	if err := inp.Validate(); err != nil {
-		logError("signUp", err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, "signUp", err)
 		return
 	}
 
 	if err := h.usersService.SignUp(r.Context(), inp); err != nil {
-		logError("signUp", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "signUp", err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
@@ -43,42 +40,40 @@ func (h *Handler) signUp(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	reqBytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		logError("signIn", err)
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "body", err)
 		return
 	}
 
 	var inp domain.SignInInput
 	if err := json.Unmarshal(reqBytes, &inp); err != nil {
-		logError("signIn", err)
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "body", err)
 		return
 	}
 
 	if err := inp.Validate(); err != nil {
-		logError("signIn", err)
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, "signIn", err)
 		return
 	}
 
 	accessToken, refreshToken, err := h.usersService.SignIn(r.Context(), inp)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			handleNotFoundError(w, err)
-			return
+		if errors.Is(err, domain.ErrInvalidCredentials) {
+			h.metrics.SignIn(metrics.ResultDenied)
+		} else {
+			h.metrics.SignIn(metrics.ResultError)
 		}
 
-		logError("signIn", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "signIn", err)
 		return
 	}
 
+	h.metrics.SignIn(metrics.ResultSuccess)
+
 	response, err := json.Marshal(map[string]string{
 		"token": accessToken,
 	})
 	if err != nil {
-		logError("signIn", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "signIn", err)
 		return
 	}
 	w.Header().Add("Set-Cookie", fmt.Sprintf("refresh-token=%s; HttpOnly", refreshToken))
@@ -86,21 +81,10 @@ func (h *Handler) signIn(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
-func handleNotFoundError(w http.ResponseWriter, err error) {
-	response, _ := json.Marshal(map[string]string{
-		"error": err.Error(),
-	})
-
-	w.Header().Add("Content-Type", "application/json")
-	w.WriteHeader(http.StatusBadRequest)
-	w.Write(response)
-}
-
 func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("refresh-token")
 	if err != nil {
-		logError("refresh", err)
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "refresh-token", err)
 		return
 	}
 
@@ -108,18 +92,24 @@ func (h *Handler) refresh(w http.ResponseWriter, r *http.Request) {
 
 	accsesToken, refreshToken, err := h.usersService.RefreshToken(r.Context(), cookie.Value)
 	if err != nil {
-		logError("refresh", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		if errors.Is(err, domain.ErrUnauthorized) {
+			h.metrics.TokenRefresh(metrics.ResultDenied)
+		} else {
+			h.metrics.TokenRefresh(metrics.ResultError)
+		}
+
+		writeError(w, r, "refresh", err)
 		return
 	}
 
+	h.metrics.TokenRefresh(metrics.ResultSuccess)
+
 	response, err := json.Marshal(map[string]string{
 		"token": accsesToken,
 	})
 
 	if err != nil {
-		logError("refresh", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "refresh", err)
 		return
 	}
 
@@ -0,0 +1,144 @@
+package rest
+
+import (
+	"encoding/json"
+	"lib/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) createAuthor(w http.ResponseWriter, r *http.Request) {
+	var inp domain.AuthorInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "CreateAuthor", err)
+		return
+	}
+
+	id, err := h.authorsService.Create(r.Context(), inp)
+	if err != nil {
+		writeError(w, r, "CreateAuthor", err)
+		return
+	}
+
+	writeJSON(w, r, "CreateAuthor", http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (h *Handler) getAllAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.authorsService.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, "GetAllAuthors", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAllAuthors", http.StatusOK, authors)
+}
+
+func (h *Handler) getAuthorByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	author, err := h.authorsService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetAuthorByID", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAuthorByID", http.StatusOK, author)
+}
+
+func (h *Handler) updateAuthor(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	var inp domain.AuthorInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "UpdateAuthor", err)
+		return
+	}
+
+	if err := h.authorsService.Update(r.Context(), id, inp); err != nil {
+		writeError(w, r, "UpdateAuthor", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) deleteAuthor(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	if err := h.authorsService.Delete(r.Context(), id); err != nil {
+		writeError(w, r, "DeleteAuthor", err)
+		return
+	}
+}
+
+func (h *Handler) mergeAuthor(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	var inp domain.AuthorMergeInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "MergeAuthor", err)
+		return
+	}
+
+	if err := h.authorsService.Merge(r.Context(), id, inp); err != nil {
+		writeError(w, r, "MergeAuthor", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) getDuplicateAuthors(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.authorsService.Duplicates(r.Context())
+	if err != nil {
+		writeError(w, r, "GetDuplicateAuthors", err)
+		return
+	}
+
+	writeJSON(w, r, "GetDuplicateAuthors", http.StatusOK, groups)
+}
+
+// writeJSON marshals v and writes it with the given status.
+func writeJSON(w http.ResponseWriter, r *http.Request, handlerName string, status int, v interface{}) {
+	response, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
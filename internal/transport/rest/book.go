@@ -1,37 +1,37 @@
 package rest
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"fmt"
 	"lib/internal/domain"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
-	log "github.com/sirupsen/logrus"
 )
 
+// maxBodySize limits JSON request bodies.
+const maxBodySize = 1 << 20
+
 func (h *Handler) createBook(w http.ResponseWriter, r *http.Request) {
-	reqBytes, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	var inp domain.CreateBookInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
 		return
 	}
 
-	book := domain.Book{}
-	err = json.Unmarshal(reqBytes, &book)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "CreateBook", err)
 		return
 	}
 
-	err = h.booksService.Create(context.TODO(), book)
+	err := h.booksService.Create(r.Context(), inp)
 	if err != nil {
-		logError("CreateBook", err)
-
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "CreateBook", err)
 		return
 	}
 
@@ -39,16 +39,21 @@ func (h *Handler) createBook(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) getAllBooks(w http.ResponseWriter, r *http.Request) {
-	books, err := h.booksService.GetAll(context.TODO())
+	filter, err := bookFilterFromQuery(r.URL.Query())
 	if err != nil {
-		logError("GetAllBooks", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "GetAllBooks", err)
+		return
+	}
+
+	books, err := h.booksService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAllBooks", err)
 		return
 	}
 
 	response, err := json.Marshal(books)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "GetAllBooks", err)
 		return
 	}
 
@@ -59,43 +64,54 @@ func (h *Handler) getAllBooks(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) updateBook(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "id", err)
 		return
 	}
 
-	reqBytes, err := ioutil.ReadAll(r.Body)
+	version, err := h.ifMatchBookVersion(r, id)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeError(w, r, "UpdateBook", err)
 		return
 	}
 
-	book := domain.UpdateBook{}
-	err = json.Unmarshal(reqBytes, &book)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	var inp domain.UpdateBookInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
 	}
 
-	err = h.booksService.Update(context.TODO(), id, book)
-	if err != nil {
-		logError("UpdateBook", err)
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "UpdateBook", err)
+		return
+	}
 
-		w.WriteHeader(http.StatusInternalServerError)
+	newVersion, err := h.booksService.Update(r.Context(), id, version, inp)
+	if err != nil {
+		writeError(w, r, "UpdateBook", err)
 		return
 	}
 
+	w.Header().Set("ETag", versionETag(newVersion))
 	w.WriteHeader(http.StatusOK)
 }
 
 func (h *Handler) deleteBook(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	version, err := h.ifMatchBookVersion(r, id)
+	if err != nil {
+		writeError(w, r, "DeleteBook", err)
+		return
 	}
 
-	err = h.booksService.Delete(context.TODO(), id)
+	err = h.booksService.Delete(r.Context(), id, version)
 	if err != nil {
-		logError("DeleteBook", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "DeleteBook", err)
 		return
 	}
 }
@@ -103,29 +119,68 @@ func (h *Handler) deleteBook(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) getBookByID(w http.ResponseWriter, r *http.Request) {
 	id, err := getIdFromRequest(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		badRequest(w, r, "id", err)
 		return
 	}
 
-	books, err := h.booksService.GetByID(context.TODO(), id)
+	book, err := h.booksService.GetByID(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, domain.ErrBookNotFound) {
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+		writeError(w, r, "GetBookByID", err)
+		return
+	}
+
+	mediaType := negotiate(r, "application/json", mediaMARCXML, mediaMARC, mediaDublinCore)
+
+	// Other representations carry a weak tag: they describe the same
+	// version, but cannot be used as a precondition for writes.
+	etag := versionETag(book.Version)
+	if mediaType != "application/json" {
+		etag = "W/" + etag
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept")
+
+	if noneMatch(r, strings.TrimPrefix(etag, "W/")) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if mediaType != "application/json" {
+		writeBookRecord(w, r, "GetBookByID", mediaType, book)
+		return
+	}
+
+	response, err := json.Marshal(book)
+	if err != nil {
+		writeError(w, r, "GetBookByID", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func (h *Handler) getBookByISBN(w http.ResponseWriter, r *http.Request) {
+	book, err := h.booksService.GetByISBN(r.Context(), mux.Vars(r)["isbn"])
+	if err != nil {
+		writeError(w, r, "GetBookByISBN", err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(book.Version))
+	writeJSON(w, r, "GetBookByISBN", http.StatusOK, book)
+}
 
-		logError("GetBookByID", err)
-		w.WriteHeader(http.StatusInternalServerError)
+func (h *Handler) getTrash(w http.ResponseWriter, r *http.Request) {
+	books, err := h.booksService.GetTrash(r.Context())
+	if err != nil {
+		writeError(w, r, "GetTrash", err)
 		return
 	}
 
 	response, err := json.Marshal(books)
 	if err != nil {
-		log.WithFields(log.Fields{
-			"handler": "getBookByID",
-			"error":   err,
-		}).Error()
-		w.WriteHeader(http.StatusInternalServerError)
+		writeError(w, r, "GetTrash", err)
 		return
 	}
 
@@ -133,6 +188,88 @@ func (h *Handler) getBookByID(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
+func (h *Handler) restoreBook(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	if err := h.booksService.Restore(r.Context(), id); err != nil {
+		writeError(w, r, "RestoreBook", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeJSON strictly decodes a single JSON document from the request body
+// into v, rejecting unknown fields and bodies over maxBodySize.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return fmt.Errorf("body must not exceed %d bytes", maxErr.Limit)
+		}
+		return err
+	}
+
+	if dec.More() {
+		return errors.New("body must contain a single JSON object")
+	}
+
+	return nil
+}
+
+func bookFilterFromQuery(q url.Values) (domain.BookFilter, error) {
+	filter := domain.BookFilter{
+		Query:  q.Get("q"),
+		Name:   q.Get("name"),
+		Author: q.Get("author"),
+		Sort:   q.Get("sort"),
+	}
+
+	switch filter.Sort {
+	case "", domain.BookSortID, domain.BookSortNewest, domain.BookSortRating:
+	default:
+		return filter, invalidParam("sort", "must be one of: id newest rating")
+	}
+
+	var err error
+	if filter.PublisherID, err = optionalInt(q, "publisher_id"); err != nil {
+		return filter, err
+	}
+
+	if filter.MinRating, err = optionalInt(q, "min_rating"); err != nil {
+		return filter, err
+	}
+
+	if filter.PublishedFrom, err = optionalInt(q, "published_from"); err != nil {
+		return filter, err
+	}
+
+	if filter.PublishedTo, err = optionalInt(q, "published_to"); err != nil {
+		return filter, err
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return filter, invalidParam("limit", "must be a positive integer")
+		}
+	}
+
+	if v := q.Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil || filter.Offset < 0 {
+			return filter, invalidParam("offset", "must be a non-negative integer")
+		}
+	}
+
+	return filter, nil
+}
+
 func getIdFromRequest(r *http.Request) (int64, error) {
 	vars := mux.Vars(r)
 	id, err := strconv.ParseInt(vars["id"], 10, 64)
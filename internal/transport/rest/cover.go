@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"lib/internal/domain"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxCoverSize limits uploaded cover images.
+	maxCoverSize = 8 << 20
+
+	// coverFormOverhead allows for the multipart framing around the image.
+	coverFormOverhead = 64 << 10
+
+	// coverMaxAge is how long clients may reuse a cover without revalidating.
+	coverMaxAge = time.Hour
+)
+
+// putCover replaces the cover of a book with the image in the "cover" part
+// of a multipart/form-data body.
+func (h *Handler) putCover(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCoverSize+coverFormOverhead)
+
+	data, err := coverFromForm(r)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			err = fmt.Errorf("%w: cover must not exceed %d bytes", domain.ErrTooLarge, maxCoverSize)
+		}
+		writeError(w, r, "PutCover", err)
+		return
+	}
+
+	cover, err := h.coversService.Put(r.Context(), id, data)
+	if err != nil {
+		writeError(w, r, "PutCover", err)
+		return
+	}
+
+	writeJSON(w, r, "PutCover", http.StatusOK, cover)
+}
+
+// coverFromForm reads the "cover" file of a multipart/form-data body.
+func coverFromForm(r *http.Request) ([]byte, error) {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, invalidParam("body", err.Error())
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, invalidParam("cover", "is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() != "cover" {
+			part.Close()
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxCoverSize+1))
+		part.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(data) > maxCoverSize {
+			return nil, fmt.Errorf("%w: cover must not exceed %d bytes", domain.ErrTooLarge, maxCoverSize)
+		}
+
+		return data, nil
+	}
+}
+
+// getCover serves the cover of a book, or one of its thumbnails, with
+// validators derived from the checksum and upload time of the cover.
+func (h *Handler) getCover(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	size, err := domain.ParseCoverSize(r.URL.Query().Get("size"))
+	if err != nil {
+		writeError(w, r, "GetCover", err)
+		return
+	}
+
+	cover, err := h.coversService.Get(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetCover", err)
+		return
+	}
+
+	etag := `"` + cover.Checksum[:16] + "-" + size + `"`
+	modified := cover.UpdatedAt.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.Itoa(int(coverMaxAge.Seconds())))
+
+	if noneMatch(r, etag) || notModifiedSince(r, modified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rc, err := h.coversService.Open(r.Context(), id, size)
+	if err != nil {
+		writeError(w, r, "GetCover", err)
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", cover.MediaType(size))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if size == domain.CoverOriginal {
+		w.Header().Set("Content-Length", strconv.FormatInt(cover.Size, 10))
+	}
+
+	if _, err := io.Copy(w, rc); err != nil {
+		logError("GetCover", err)
+	}
+}
+
+func (h *Handler) deleteCover(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	if err := h.coversService.Delete(r.Context(), id); err != nil {
+		writeError(w, r, "DeleteCover", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// notModifiedSince reports whether If-Modified-Since is at or after
+// modified. The header is ignored when If-None-Match is present.
+func notModifiedSince(r *http.Request, modified time.Time) bool {
+	if r.Header.Get("If-None-Match") != "" {
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	return !modified.After(since)
+}
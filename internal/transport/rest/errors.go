@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"lib/internal/domain"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// problem is an RFC 7807 problem details document.
+type problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	TraceID  string              `json:"trace_id,omitempty"`
+	Errors   []domain.FieldError `json:"errors,omitempty"`
+}
+
+// errorStatus maps domain error kinds onto HTTP status codes, in match order.
+var errorStatus = []struct {
+	kind   error
+	status int
+}{
+	{domain.ErrValidation, http.StatusBadRequest},
+	{domain.ErrUnauthorized, http.StatusUnauthorized},
+	{domain.ErrForbidden, http.StatusForbidden},
+	{domain.ErrNotFound, http.StatusNotFound},
+	{domain.ErrConflict, http.StatusConflict},
+	{domain.ErrRateLimited, http.StatusTooManyRequests},
+	{domain.ErrTooLarge, http.StatusRequestEntityTooLarge},
+	{domain.ErrUnsupported, http.StatusUnsupportedMediaType},
+	{domain.ErrPreconditionFailed, http.StatusPreconditionFailed},
+	{domain.ErrPreconditionRequired, http.StatusPreconditionRequired},
+}
+
+// writeError renders err as application/problem+json. Errors outside the
+// domain taxonomy are logged under handlerName and reported as a 500
+// without leaking their message.
+func writeError(w http.ResponseWriter, r *http.Request, handlerName string, err error) {
+	status := http.StatusInternalServerError
+	for _, m := range errorStatus {
+		if errors.Is(err, m.kind) {
+			status = m.status
+			break
+		}
+	}
+
+	p := problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: r.URL.Path,
+	}
+
+	if status == http.StatusInternalServerError {
+		logError(handlerName, err)
+	} else {
+		p.Detail = err.Error()
+	}
+
+	var verr *domain.ValidationError
+	if errors.As(err, &verr) {
+		p.Detail = domain.ErrValidation.Error()
+		p.Errors = verr.Fields
+	}
+
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		p.TraceID = sc.TraceID().String()
+	}
+
+	response, _ := json.Marshal(p)
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
+
+// badRequest reports malformed input that never reached the services.
+func badRequest(w http.ResponseWriter, r *http.Request, field string, err error) {
+	writeError(w, r, "", domain.NewValidationError(domain.FieldError{
+		Field:   field,
+		Message: err.Error(),
+	}))
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"lib/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	fields := []domain.FieldError{{Field: "name", Message: "is required"}}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+		fields []domain.FieldError
+	}{
+		{"validation", domain.NewValidationError(fields...), http.StatusBadRequest, "validation failed", fields},
+		{"unauthorized", domain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid email or password", nil},
+		{"forbidden", domain.ErrForbidden, http.StatusForbidden, "forbidden", nil},
+		{"not found", domain.ErrBookNotFound, http.StatusNotFound, "book not found", nil},
+		{"conflict", domain.ErrISBNTaken, http.StatusConflict, "isbn is already assigned to another book", nil},
+		{"rate limited", domain.ErrRateLimited, http.StatusTooManyRequests, "rate limited", nil},
+		{"too large", domain.ErrTooLarge, http.StatusRequestEntityTooLarge, "too large", nil},
+		{"unsupported", domain.ErrCoverType, http.StatusUnsupportedMediaType, "cover must be a JPEG, PNG or GIF image", nil},
+		{"precondition failed", domain.ErrBookVersionMismatch, http.StatusPreconditionFailed, "book was modified by another request", nil},
+		{"precondition required", domain.ErrPreconditionRequired, http.StatusPreconditionRequired, "precondition required", nil},
+		{"wrapped", fmt.Errorf("loading: %w", domain.ErrItemNotFound), http.StatusNotFound, "loading: item not found", nil},
+		{"internal", errors.New("connection refused"), http.StatusInternalServerError, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeError(w, httptest.NewRequest("GET", "/books/1", nil), "Test", tt.err)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+
+			var p problem
+			if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+				t.Fatal(err)
+			}
+
+			if p.Status != tt.status || p.Title != http.StatusText(tt.status) || p.Instance != "/books/1" {
+				t.Errorf("problem = %+v", p)
+			}
+			if p.Detail != tt.detail {
+				t.Errorf("detail = %q, want %q", p.Detail, tt.detail)
+			}
+			if !reflect.DeepEqual(p.Errors, tt.fields) {
+				t.Errorf("errors = %+v, want %+v", p.Errors, tt.fields)
+			}
+		})
+	}
+}
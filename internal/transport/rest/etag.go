@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"fmt"
+	"lib/internal/domain"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionETag returns the strong entity tag for a resource version.
+func versionETag(version int) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// errPreconditionFailed reports an If-Match header that matches no version.
+var errPreconditionFailed = fmt.Errorf("%w: If-Match does not match the current ETag", domain.ErrPreconditionFailed)
+
+// ifMatchVersions extracts the versions listed in a mandatory If-Match
+// header, which may be repeated and hold a comma-separated list of tags
+// (RFC 9110, section 13.1.1). "*" matches any existing version and yields
+// no versions. Weak tags and tags that are not ours never match; a header
+// without a single matching candidate fails the precondition.
+func ifMatchVersions(r *http.Request) ([]int, error) {
+	header := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	if header == "" {
+		return nil, fmt.Errorf("%w: If-Match header is required", domain.ErrPreconditionRequired)
+	}
+
+	if header == "*" {
+		return nil, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if !strings.HasPrefix(tag, `"v`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+
+		version, err := strconv.ParseUint(tag[2:len(tag)-1], 10, 31)
+		if err == nil && version > 0 {
+			versions = append(versions, int(version))
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, errPreconditionFailed
+	}
+
+	return versions, nil
+}
+
+// ifMatchBookVersion returns the version a write to the book must expect
+// under If-Match, 0 for any. A single tag is checked by the write itself;
+// with several, the current version is looked up and expected only if it
+// is listed, so that the write still fails if the book changes meanwhile.
+func (h *Handler) ifMatchBookVersion(r *http.Request, id int64) (int, error) {
+	versions, err := ifMatchVersions(r)
+	if err != nil || len(versions) == 0 {
+		return 0, err
+	}
+
+	if len(versions) == 1 {
+		return versions[0], nil
+	}
+
+	book, err := h.booksService.GetByID(r.Context(), id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, version := range versions {
+		if version == book.Version {
+			return version, nil
+		}
+	}
+
+	return 0, errPreconditionFailed
+}
+
+// noneMatch reports whether If-None-Match matches etag, using the weak
+// comparison RFC 9110 prescribes for this header.
+func noneMatch(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"errors"
+	"lib/internal/domain"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestIfMatchVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    []int
+		err     error
+	}{
+		{"missing", nil, nil, domain.ErrPreconditionRequired},
+		{"blank", []string{" "}, nil, domain.ErrPreconditionRequired},
+		{"any", []string{"*"}, nil, nil},
+		{"single", []string{`"v3"`}, []int{3}, nil},
+		{"list", []string{`"v3", "v4"`}, []int{3, 4}, nil},
+		{"repeated header", []string{`"v3"`, `"v5"`}, []int{3, 5}, nil},
+		{"foreign tags skipped", []string{`"abc", W/"v2", "v7"`}, []int{7}, nil},
+		{"weak only", []string{`W/"v2"`}, nil, domain.ErrPreconditionFailed},
+		{"unquoted", []string{"v2"}, nil, domain.ErrPreconditionFailed},
+		{"zero", []string{`"v0"`}, nil, domain.ErrPreconditionFailed},
+		{"signed", []string{`"v-1", "v+1"`}, nil, domain.ErrPreconditionFailed},
+		{"star in list", []string{`*, "v2"`}, []int{2}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/books/1", nil)
+			for _, h := range tt.headers {
+				r.Header.Add("If-Match", h)
+			}
+
+			got, err := ifMatchVersions(r)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("versions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"lib/internal/bookio"
+	"net/http"
+	"strings"
+)
+
+// exportBooks streams the catalog as CSV, NDJSON, MARC or MARCXML, taking the
+// same filters as the book listing. The response is gzip-compressed when
+// the client accepts it. Rows are written as they are read, so a failure
+// after the first byte can only be signalled by aborting the connection;
+// earlier failures get a regular error response.
+func (h *Handler) exportBooks(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = bookio.FormatCSV
+	}
+
+	filter, err := bookFilterFromQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, r, "ExportBooks", err)
+		return
+	}
+
+	body := &sentWriter{w: w}
+
+	var (
+		out io.Writer = body
+		gz  *gzip.Writer
+	)
+	if acceptsGzip(r) {
+		gz = gzip.NewWriter(w)
+		out = gz
+	}
+
+	enc, err := bookio.NewWriter(format, out)
+	if err != nil {
+		badRequest(w, r, "format", fmt.Errorf("must be %s, %s, %s or %s", bookio.FormatCSV, bookio.FormatNDJSON, bookio.FormatMARC, bookio.FormatMARCXML))
+		return
+	}
+
+	if gz != nil {
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	w.Header().Add("Vary", "Accept-Encoding")
+	w.Header().Set("Content-Type", bookio.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="books.%s"`, exportExtension(format)))
+
+	err = h.booksService.Export(r.Context(), filter, enc.Write)
+	if err == nil {
+		err = enc.Close()
+	}
+	if err == nil && gz != nil {
+		err = gz.Close()
+	}
+	if err == nil {
+		return
+	}
+
+	if !body.sent {
+		for _, name := range []string{"Content-Encoding", "Content-Disposition", "Content-Type", "Vary"} {
+			w.Header().Del(name)
+		}
+		writeError(w, r, "ExportBooks", err)
+		return
+	}
+
+	logError("ExportBooks", err)
+	panic(http.ErrAbortHandler)
+}
+
+// sentWriter records whether any byte reached w.
+type sentWriter struct {
+	w    io.Writer
+	sent bool
+}
+
+func (s *sentWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		s.sent = true
+	}
+	return s.w.Write(p)
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, enc := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(enc), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+func exportExtension(format string) string {
+	switch format {
+	case bookio.FormatMARC:
+		return "mrc"
+	case bookio.FormatMARCXML:
+		return "xml"
+	default:
+		return format
+	}
+}
@@ -2,18 +2,31 @@ package rest
 
 import (
 	"context"
+	"io"
+	"lib/internal/bookio"
 	"lib/internal/domain"
+	"lib/internal/metrics"
+	"lib/internal/opds"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 type Books interface {
-	Create(ctx context.Context, book domain.Book) error
-	Update(ctx context.Context, id int64, inp domain.UpdateBook) error
-	Delete(ctx context.Context, id int64) error
-	GetAll(ctx context.Context) ([]domain.Book, error)
+	Create(ctx context.Context, inp domain.CreateBookInput) error
+	Update(ctx context.Context, id int64, version int, inp domain.UpdateBookInput) (int, error)
+	Delete(ctx context.Context, id int64, version int) error
+	GetAll(ctx context.Context, filter domain.BookFilter) ([]domain.Book, error)
+	Export(ctx context.Context, filter domain.BookFilter, fn func(domain.Book) error) error
 	GetByID(ctx context.Context, id int64) (domain.Book, error)
+	GetByISBN(ctx context.Context, isbn string) (domain.Book, error)
+	GetTrash(ctx context.Context) ([]domain.Book, error)
+	Restore(ctx context.Context, id int64) error
+	GetRevisions(ctx context.Context, id int64) ([]domain.BookRevision, error)
+	GetRevision(ctx context.Context, id int64, revision int) (domain.BookRevision, error)
+	Revert(ctx context.Context, id int64, revision, version int) (int, error)
 }
 
 type User interface {
@@ -21,24 +34,133 @@ type User interface {
 	SignUp(ctx context.Context, inp domain.SignUpInput) error
 	ParseToken(ctx context.Context, accessToken string) (int64, error)
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
+	GetByID(ctx context.Context, id int64) (domain.User, error)
+}
+
+type Authors interface {
+	Create(ctx context.Context, inp domain.AuthorInput) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Author, error)
+	GetByID(ctx context.Context, id int64) (domain.Author, error)
+	Update(ctx context.Context, id int64, inp domain.AuthorInput) error
+	Delete(ctx context.Context, id int64) error
+	Merge(ctx context.Context, from int64, inp domain.AuthorMergeInput) error
+	Duplicates(ctx context.Context) ([][]domain.Author, error)
+}
+
+type Publishers interface {
+	Create(ctx context.Context, inp domain.PublisherInput) (int64, error)
+	GetAll(ctx context.Context) ([]domain.Publisher, error)
+	GetByID(ctx context.Context, id int64) (domain.Publisher, error)
+	Update(ctx context.Context, id int64, inp domain.PublisherInput) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type Items interface {
+	Create(ctx context.Context, inp domain.ItemInput) (int64, error)
+	GetAll(ctx context.Context, filter domain.ItemFilter) ([]domain.Item, error)
+	GetByID(ctx context.Context, id int64) (domain.Item, error)
+	GetByBarcode(ctx context.Context, code string) (domain.Item, error)
+	Update(ctx context.Context, id int64, inp domain.ItemInput) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type Loans interface {
+	Checkout(ctx context.Context, inp domain.CheckoutInput) (domain.Loan, error)
+	Return(ctx context.Context, id int64) (domain.Loan, error)
+	Renew(ctx context.Context, id int64) (domain.Loan, error)
+	RenewOwn(ctx context.Context, userID, id int64) (domain.Loan, error)
+	GetByID(ctx context.Context, id int64) (domain.Loan, error)
+	GetAll(ctx context.Context, filter domain.LoanFilter) ([]domain.Loan, error)
+}
+
+type Holds interface {
+	Place(ctx context.Context, userID int64, inp domain.HoldInput) (domain.Hold, error)
+	Cancel(ctx context.Context, id int64) (domain.Hold, error)
+	CancelOwn(ctx context.Context, userID, id int64) (domain.Hold, error)
+	GetByID(ctx context.Context, id int64) (domain.Hold, error)
+	GetAll(ctx context.Context, filter domain.HoldFilter) ([]domain.Hold, error)
+}
+
+type Ledger interface {
+	Pay(ctx context.Context, userID int64, inp domain.CreditInput) (domain.LedgerEntry, error)
+	Waive(ctx context.Context, userID int64, inp domain.CreditInput) (domain.LedgerEntry, error)
+	GetAccount(ctx context.Context, userID int64) (domain.Account, error)
+}
+
+type Imports interface {
+	Run(ctx context.Context, format string, src bookio.Reader, dryRun bool) (domain.ImportJob, error)
+	Start(ctx context.Context, format string, src bookio.Reader, release func(), dryRun bool) (domain.ImportJob, error)
+	GetByID(ctx context.Context, id int64) (domain.ImportJob, error)
+}
+
+type Covers interface {
+	Put(ctx context.Context, bookID int64, data []byte) (domain.Cover, error)
+	Get(ctx context.Context, bookID int64) (domain.Cover, error)
+	Open(ctx context.Context, bookID int64, size string) (io.ReadCloser, error)
+	Delete(ctx context.Context, bookID int64) error
+}
+
+type Audit interface {
+	Find(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditRecord, int64, error)
+	BookHistory(ctx context.Context, id int64) ([]domain.AuditRecord, error)
+}
+
+type JobRuns interface {
+	GetAll(ctx context.Context, filter domain.JobRunFilter) ([]domain.JobRun, error)
 }
 
 type Handler struct {
-	booksService Books
-	usersService User
+	booksService      Books
+	authorsService    Authors
+	publishersService Publishers
+	itemsService      Items
+	loansService      Loans
+	holdsService      Holds
+	ledgerService     Ledger
+	importsService    Imports
+	coversService     Covers
+	usersService      User
+	auditService      Audit
+	jobsService       JobRuns
+
+	metrics   *metrics.Metrics
+	health    *health
+	publicURL string
 }
 
-func NewHandler(books Books, users User) *Handler {
+func NewHandler(books Books, authors Authors, publishers Publishers, items Items, loans Loans, holds Holds, ledger Ledger, imports Imports, covers Covers, users User, audit Audit, jobs JobRuns, m *metrics.Metrics) *Handler {
 	return &Handler{
-		booksService: books,
-		usersService: users,
+		booksService:      books,
+		authorsService:    authors,
+		publishersService: publishers,
+		itemsService:      items,
+		loansService:      loans,
+		holdsService:      holds,
+		ledgerService:     ledger,
+		importsService:    imports,
+		coversService:     covers,
+		usersService:      users,
+		auditService:      audit,
+		jobsService:       jobs,
+		metrics:           m,
+		health:            &health{},
 	}
 }
 
+// SetPublicURL sets the scheme and host that absolute links point at,
+// instead of the ones of each request.
+func (h *Handler) SetPublicURL(u string) {
+	h.publicURL = strings.TrimSuffix(u, "/")
+}
+
 func (h *Handler) InitRouter() *mux.Router {
 	r := mux.NewRouter()
 
-	r.Use(loggingMiddleware)
+	r.Use(otelmux.Middleware("lib"), traceMiddleware, loggingMiddleware, h.metricsMiddleware)
+
+	r.Handle("/metrics", h.metrics.Handler()).Methods(http.MethodGet)
+	r.HandleFunc("/healthz", h.liveness).Methods(http.MethodGet)
+	r.HandleFunc("/readyz", h.readiness).Methods(http.MethodGet)
 
 	auth := r.PathPrefix("/auth").Subrouter()
 	{
@@ -56,6 +178,119 @@ func (h *Handler) InitRouter() *mux.Router {
 		books.HandleFunc("/{id:[0-9]+}", h.updateBook).Methods(http.MethodPut)
 		books.HandleFunc("/{id:[0-9]+}", h.deleteBook).Methods(http.MethodDelete)
 		books.HandleFunc("/{id:[0-9]+}", h.getBookByID).Methods(http.MethodGet)
+		books.HandleFunc("/export", h.exportBooks).Methods(http.MethodGet)
+		books.HandleFunc("/import", h.importBooks).Methods(http.MethodPost)
+		books.HandleFunc("/import/{id:[0-9]+}", h.getImport).Methods(http.MethodGet)
+		books.HandleFunc("/isbn/{isbn}", h.getBookByISBN).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/cover", h.putCover).Methods(http.MethodPut)
+		books.HandleFunc("/{id:[0-9]+}/cover", h.getCover).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/cover", h.deleteCover).Methods(http.MethodDelete)
+		books.Handle("/{id:[0-9]+}/history", h.adminMiddleware(http.HandlerFunc(h.getBookHistory))).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/revisions", h.getBookRevisions).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/revisions/{revision:[0-9]+}", h.getBookRevision).Methods(http.MethodGet)
+		books.HandleFunc("/{id:[0-9]+}/revisions/{revision:[0-9]+}/revert", h.revertBook).Methods(http.MethodPost)
+
+		books.Handle("/trash", h.adminMiddleware(http.HandlerFunc(h.getTrash))).Methods(http.MethodGet)
+		books.Handle("/{id:[0-9]+}/restore", h.adminMiddleware(http.HandlerFunc(h.restoreBook))).Methods(http.MethodPost)
+	}
+
+	authors := r.PathPrefix("/authors").Subrouter()
+	{
+		authors.Use(h.authMiddleware)
+
+		authors.HandleFunc("/", h.createAuthor).Methods(http.MethodPost)
+		authors.HandleFunc("/", h.getAllAuthors).Methods(http.MethodGet)
+		authors.HandleFunc("/duplicates", h.getDuplicateAuthors).Methods(http.MethodGet)
+		authors.HandleFunc("/{id:[0-9]+}", h.getAuthorByID).Methods(http.MethodGet)
+		authors.HandleFunc("/{id:[0-9]+}", h.updateAuthor).Methods(http.MethodPut)
+		authors.Handle("/{id:[0-9]+}", h.adminMiddleware(http.HandlerFunc(h.deleteAuthor))).Methods(http.MethodDelete)
+		authors.Handle("/{id:[0-9]+}/merge", h.adminMiddleware(http.HandlerFunc(h.mergeAuthor))).Methods(http.MethodPost)
+	}
+
+	publishers := r.PathPrefix("/publishers").Subrouter()
+	{
+		publishers.Use(h.authMiddleware)
+
+		publishers.HandleFunc("/", h.createPublisher).Methods(http.MethodPost)
+		publishers.HandleFunc("/", h.getAllPublishers).Methods(http.MethodGet)
+		publishers.HandleFunc("/{id:[0-9]+}", h.getPublisherByID).Methods(http.MethodGet)
+		publishers.Handle("/{id:[0-9]+}", h.adminMiddleware(http.HandlerFunc(h.updatePublisher))).Methods(http.MethodPut)
+		publishers.Handle("/{id:[0-9]+}", h.adminMiddleware(http.HandlerFunc(h.deletePublisher))).Methods(http.MethodDelete)
+	}
+
+	items := r.PathPrefix("/items").Subrouter()
+	{
+		items.Use(h.authMiddleware)
+
+		items.Handle("/", h.adminMiddleware(http.HandlerFunc(h.createItem))).Methods(http.MethodPost)
+		items.HandleFunc("/", h.getAllItems).Methods(http.MethodGet)
+		items.HandleFunc("/barcode/{barcode}", h.getItemByBarcode).Methods(http.MethodGet)
+		items.HandleFunc("/{id:[0-9]+}", h.getItemByID).Methods(http.MethodGet)
+		items.Handle("/{id:[0-9]+}", h.adminMiddleware(http.HandlerFunc(h.updateItem))).Methods(http.MethodPut)
+		items.Handle("/{id:[0-9]+}", h.adminMiddleware(http.HandlerFunc(h.deleteItem))).Methods(http.MethodDelete)
+	}
+
+	loans := r.PathPrefix("/loans").Subrouter()
+	{
+		loans.Use(h.authMiddleware, h.adminMiddleware)
+
+		loans.HandleFunc("/", h.checkout).Methods(http.MethodPost)
+		loans.HandleFunc("/", h.getAllLoans).Methods(http.MethodGet)
+		loans.HandleFunc("/{id:[0-9]+}", h.getLoanByID).Methods(http.MethodGet)
+		loans.HandleFunc("/{id:[0-9]+}/return", h.returnLoan).Methods(http.MethodPost)
+		loans.HandleFunc("/{id:[0-9]+}/renew", h.renewLoan).Methods(http.MethodPost)
+	}
+
+	holds := r.PathPrefix("/holds").Subrouter()
+	{
+		holds.Use(h.authMiddleware, h.adminMiddleware)
+
+		holds.HandleFunc("/", h.getAllHolds).Methods(http.MethodGet)
+		holds.HandleFunc("/{id:[0-9]+}", h.getHoldByID).Methods(http.MethodGet)
+		holds.HandleFunc("/{id:[0-9]+}", h.cancelHold).Methods(http.MethodDelete)
+	}
+
+	accounts := r.PathPrefix("/accounts").Subrouter()
+	{
+		accounts.Use(h.authMiddleware, h.adminMiddleware)
+
+		accounts.HandleFunc("/{id:[0-9]+}", h.getAccount).Methods(http.MethodGet)
+		accounts.HandleFunc("/{id:[0-9]+}/payments", h.recordPayment).Methods(http.MethodPost)
+		accounts.HandleFunc("/{id:[0-9]+}/waivers", h.recordWaiver).Methods(http.MethodPost)
+	}
+
+	me := r.PathPrefix("/me").Subrouter()
+	{
+		me.Use(h.authMiddleware)
+
+		me.HandleFunc("/loans", h.getMyLoans).Methods(http.MethodGet)
+		me.HandleFunc("/loans/{id:[0-9]+}/renew", h.renewMyLoan).Methods(http.MethodPost)
+		me.HandleFunc("/holds", h.getMyHolds).Methods(http.MethodGet)
+		me.HandleFunc("/holds", h.placeHold).Methods(http.MethodPost)
+		me.HandleFunc("/holds/{id:[0-9]+}", h.cancelMyHold).Methods(http.MethodDelete)
+		me.HandleFunc("/account", h.getMyAccount).Methods(http.MethodGet)
+	}
+
+	catalog := r.PathPrefix(opds.Root).Subrouter()
+	{
+		catalog.Use(h.authMiddleware)
+
+		catalog.HandleFunc("", h.getOPDSRoot).Methods(http.MethodGet)
+		catalog.HandleFunc("/", h.getOPDSRoot).Methods(http.MethodGet)
+		catalog.HandleFunc("/search", h.searchOPDS).Methods(http.MethodGet)
+		catalog.HandleFunc("/opensearch.xml", h.getOPDSOpenSearch).Methods(http.MethodGet)
+
+		for _, section := range opds.Sections {
+			catalog.HandleFunc(strings.TrimPrefix(section.Path, opds.Root), h.getOPDSSection(section)).Methods(http.MethodGet)
+		}
+	}
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	{
+		admin.Use(h.authMiddleware, h.adminMiddleware)
+
+		admin.HandleFunc("/audit", h.getAuditRecords).Methods(http.MethodGet)
+		admin.HandleFunc("/jobs/runs", h.getJobRuns).Methods(http.MethodGet)
 	}
 
 	return r
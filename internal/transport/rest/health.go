@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthChecker is a dependency probed by the readiness endpoint.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type healthCheck struct {
+	checker HealthChecker
+	timeout time.Duration
+}
+
+type health struct {
+	mu       sync.RWMutex
+	checks   []healthCheck
+	stopping atomic.Bool
+}
+
+type checkResult struct {
+	Status   string `json:"status"`
+	Duration string `json:"duration"`
+	Error    string `json:"error,omitempty"`
+}
+
+type healthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// AddHealthCheck registers a readiness dependency. Each check gets its own
+// timeout; all checks run concurrently on every /readyz request.
+func (h *Handler) AddHealthCheck(checker HealthChecker, timeout time.Duration) {
+	h.health.mu.Lock()
+	defer h.health.mu.Unlock()
+
+	h.health.checks = append(h.health.checks, healthCheck{checker: checker, timeout: timeout})
+}
+
+// SetNotReady makes /readyz fail from now on. It is called when graceful
+// shutdown starts so that the orchestrator stops routing traffic here.
+func (h *Handler) SetNotReady() {
+	h.health.stopping.Store(true)
+}
+
+func (h *Handler) liveness(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, http.StatusOK, healthReport{Status: "ok"})
+}
+
+func (h *Handler) readiness(w http.ResponseWriter, r *http.Request) {
+	if h.health.stopping.Load() {
+		writeHealthReport(w, http.StatusServiceUnavailable, healthReport{Status: "shutting down"})
+		return
+	}
+
+	h.health.mu.RLock()
+	checks := h.health.checks
+	h.health.mu.RUnlock()
+
+	report := healthReport{
+		Status: "ok",
+		Checks: make(map[string]checkResult, len(checks)),
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c healthCheck) {
+			defer wg.Done()
+
+			result := runHealthCheck(r.Context(), c)
+
+			mu.Lock()
+			report.Checks[c.checker.Name()] = result
+			if result.Status != "ok" {
+				report.Status = "fail"
+			}
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if report.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+
+	writeHealthReport(w, status, report)
+}
+
+func runHealthCheck(ctx context.Context, c healthCheck) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := c.checker.Check(ctx)
+
+	result := checkResult{
+		Status:   "ok",
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		result.Status = "fail"
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func writeHealthReport(w http.ResponseWriter, status int, report healthReport) {
+	response, err := json.Marshal(report)
+	if err != nil {
+		logError("health", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(response)
+}
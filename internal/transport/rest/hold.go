@@ -0,0 +1,147 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) placeHold(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "PlaceHold", domain.ErrUnauthorized)
+		return
+	}
+
+	var inp domain.HoldInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "PlaceHold", err)
+		return
+	}
+
+	hold, err := h.holdsService.Place(r.Context(), userID, inp)
+	if err != nil {
+		writeError(w, r, "PlaceHold", err)
+		return
+	}
+
+	writeJSON(w, r, "PlaceHold", http.StatusCreated, hold)
+}
+
+// getMyHolds lists the active holds of the current user with their queue
+// positions, or all of them with history=true.
+func (h *Handler) getMyHolds(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "GetMyHolds", domain.ErrUnauthorized)
+		return
+	}
+
+	history, err := optionalBool(r, "history")
+	if err != nil {
+		badRequest(w, r, "history", err)
+		return
+	}
+
+	holds, err := h.holdsService.GetAll(r.Context(), domain.HoldFilter{UserID: &userID, Active: !history})
+	if err != nil {
+		writeError(w, r, "GetMyHolds", err)
+		return
+	}
+
+	writeJSON(w, r, "GetMyHolds", http.StatusOK, holds)
+}
+
+func (h *Handler) cancelMyHold(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "CancelMyHold", domain.ErrUnauthorized)
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	hold, err := h.holdsService.CancelOwn(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, "CancelMyHold", err)
+		return
+	}
+
+	writeJSON(w, r, "CancelMyHold", http.StatusOK, hold)
+}
+
+func (h *Handler) cancelHold(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	hold, err := h.holdsService.Cancel(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "CancelHold", err)
+		return
+	}
+
+	writeJSON(w, r, "CancelHold", http.StatusOK, hold)
+}
+
+func (h *Handler) getHoldByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	hold, err := h.holdsService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetHoldByID", err)
+		return
+	}
+
+	writeJSON(w, r, "GetHoldByID", http.StatusOK, hold)
+}
+
+func (h *Handler) getAllHolds(w http.ResponseWriter, r *http.Request) {
+	filter, err := holdFilterFromQuery(r)
+	if err != nil {
+		writeError(w, r, "GetAllHolds", err)
+		return
+	}
+
+	holds, err := h.holdsService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAllHolds", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAllHolds", http.StatusOK, holds)
+}
+
+func holdFilterFromQuery(r *http.Request) (domain.HoldFilter, error) {
+	var (
+		filter domain.HoldFilter
+		err    error
+		q      = r.URL.Query()
+	)
+
+	if filter.UserID, err = optionalInt(q, "user_id"); err != nil {
+		return filter, err
+	}
+	if filter.BookID, err = optionalInt(q, "book_id"); err != nil {
+		return filter, err
+	}
+	if filter.Active, err = optionalBool(r, "active"); err != nil {
+		return filter, invalidParam("active", "must be a boolean")
+	}
+
+	return filter, nil
+}
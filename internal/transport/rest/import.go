@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"lib/internal/bookio"
+	"lib/internal/domain"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+const (
+	// maxImportSize limits import uploads.
+	maxImportSize = 256 << 20
+	// syncImportSize is the largest upload imported within the request.
+	// Larger uploads, and uploads of unknown length, become background jobs.
+	syncImportSize = maxBodySize
+)
+
+// importBooks loads books from a CSV, NDJSON, MARC or MARCXML upload. The
+// format comes from the format query parameter or the Content-Type. With
+// dry_run=true every row is checked against the database and nothing is
+// kept. Small uploads are imported before responding; large ones, or any
+// upload with async=true, are spooled to disk and imported in the
+// background, and the response points at the job to poll.
+func (h *Handler) importBooks(w http.ResponseWriter, r *http.Request) {
+	format, err := importFormat(r)
+	if err != nil {
+		badRequest(w, r, "format", err)
+		return
+	}
+
+	dryRun, err := optionalBool(r, "dry_run")
+	if err != nil {
+		badRequest(w, r, "dry_run", err)
+		return
+	}
+
+	async, err := optionalBool(r, "async")
+	if err != nil {
+		badRequest(w, r, "async", err)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	if !async && r.ContentLength >= 0 && r.ContentLength <= syncImportSize {
+		src, err := bookio.NewReader(format, body)
+		if err != nil {
+			badRequest(w, r, "body", err)
+			return
+		}
+
+		job, err := h.importsService.Run(r.Context(), format, src, dryRun)
+		if err != nil {
+			writeError(w, r, "ImportBooks", err)
+			return
+		}
+
+		writeJSON(w, r, "ImportBooks", http.StatusOK, job)
+		return
+	}
+
+	file, err := spool(body)
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			badRequest(w, r, "body", fmt.Errorf("body must not exceed %d bytes", maxErr.Limit))
+			return
+		}
+		writeError(w, r, "ImportBooks", err)
+		return
+	}
+
+	release := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	src, err := bookio.NewReader(format, file)
+	if err != nil {
+		release()
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	job, err := h.importsService.Start(r.Context(), format, src, release, dryRun)
+	if err != nil {
+		writeError(w, r, "ImportBooks", err)
+		return
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/books/import/%d", job.ID))
+	writeJSON(w, r, "ImportBooks", http.StatusAccepted, job)
+}
+
+// getImport reports on an import job. Jobs are only visible to the user
+// who started them and to admins; anyone else gets a 404.
+func (h *Handler) getImport(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	job, err := h.importsService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetImport", err)
+		return
+	}
+
+	if userID, _ := domain.UserIDFromContext(r.Context()); job.ActorID != userID {
+		user, err := h.usersService.GetByID(r.Context(), userID)
+		if err != nil {
+			writeError(w, r, "GetImport", err)
+			return
+		}
+
+		if !user.IsAdmin() {
+			writeError(w, r, "GetImport", domain.ErrImportNotFound)
+			return
+		}
+	}
+
+	writeJSON(w, r, "GetImport", http.StatusOK, job)
+}
+
+// importFormat picks the import format from the format query parameter,
+// falling back to the Content-Type.
+func importFormat(r *http.Request) (string, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		switch format {
+		case domain.ImportFormatCSV, domain.ImportFormatNDJSON, domain.ImportFormatMARC, domain.ImportFormatMARCXML:
+			return format, nil
+		}
+		return "", fmt.Errorf("must be %s, %s, %s or %s",
+			domain.ImportFormatCSV, domain.ImportFormatNDJSON, domain.ImportFormatMARC, domain.ImportFormatMARCXML)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return domain.ImportFormatCSV, nil
+	case "application/x-ndjson", "application/jsonl":
+		return domain.ImportFormatNDJSON, nil
+	case "application/marc":
+		return domain.ImportFormatMARC, nil
+	case "application/marcxml+xml":
+		return domain.ImportFormatMARCXML, nil
+	}
+
+	return "", errors.New("set format or a text/csv, application/x-ndjson, application/marc or application/marcxml+xml Content-Type")
+}
+
+func optionalBool(r *http.Request, name string) (bool, error) {
+	s := r.URL.Query().Get(name)
+	if s == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(s)
+}
+
+// spool copies an upload to a temporary file positioned at its start.
+func spool(body io.Reader) (*os.File, error) {
+	file, err := os.CreateTemp("", "lib-import-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	return file, nil
+}
@@ -0,0 +1,124 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) createItem(w http.ResponseWriter, r *http.Request) {
+	var inp domain.ItemInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "CreateItem", err)
+		return
+	}
+
+	id, err := h.itemsService.Create(r.Context(), inp)
+	if err != nil {
+		writeError(w, r, "CreateItem", err)
+		return
+	}
+
+	writeJSON(w, r, "CreateItem", http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (h *Handler) getAllItems(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	bookID, err := optionalInt(q, "book_id")
+	if err != nil {
+		writeError(w, r, "GetAllItems", err)
+		return
+	}
+
+	filter := domain.ItemFilter{BookID: bookID, Status: q.Get("status")}
+	switch filter.Status {
+	case "", domain.ItemAvailable, domain.ItemOnLoan, domain.ItemLost, domain.ItemInRepair:
+	default:
+		writeError(w, r, "GetAllItems", invalidParam("status", "must be one of: available on_loan lost in_repair"))
+		return
+	}
+
+	items, err := h.itemsService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAllItems", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAllItems", http.StatusOK, items)
+}
+
+func (h *Handler) getItemByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	item, err := h.itemsService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetItemByID", err)
+		return
+	}
+
+	writeJSON(w, r, "GetItemByID", http.StatusOK, item)
+}
+
+func (h *Handler) getItemByBarcode(w http.ResponseWriter, r *http.Request) {
+	item, err := h.itemsService.GetByBarcode(r.Context(), mux.Vars(r)["barcode"])
+	if err != nil {
+		writeError(w, r, "GetItemByBarcode", err)
+		return
+	}
+
+	writeJSON(w, r, "GetItemByBarcode", http.StatusOK, item)
+}
+
+func (h *Handler) updateItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	var inp domain.ItemInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "UpdateItem", err)
+		return
+	}
+
+	if err := h.itemsService.Update(r.Context(), id, inp); err != nil {
+		writeError(w, r, "UpdateItem", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) deleteItem(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	if err := h.itemsService.Delete(r.Context(), id); err != nil {
+		writeError(w, r, "DeleteItem", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
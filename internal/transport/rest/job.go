@@ -0,0 +1,36 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultJobRunsLimit caps the job run history returned without a limit.
+	defaultJobRunsLimit = 100
+	maxJobRunsLimit     = 1000
+)
+
+// getJobRuns lists scheduled job runs, most recent first, optionally for a
+// single job.
+func (h *Handler) getJobRuns(w http.ResponseWriter, r *http.Request) {
+	filter := domain.JobRunFilter{Job: r.URL.Query().Get("job"), Limit: defaultJobRunsLimit}
+
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			writeError(w, r, "GetJobRuns", invalidParam("limit", "must be a positive integer"))
+			return
+		}
+		filter.Limit = min(limit, maxJobRunsLimit)
+	}
+
+	runs, err := h.jobsService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetJobRuns", err)
+		return
+	}
+
+	writeJSON(w, r, "GetJobRuns", http.StatusOK, runs)
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+)
+
+// getAccount returns the balance and ledger of the user named by id.
+func (h *Handler) getAccount(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	account, err := h.ledgerService.GetAccount(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetAccount", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAccount", http.StatusOK, account)
+}
+
+func (h *Handler) getMyAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "GetMyAccount", domain.ErrUnauthorized)
+		return
+	}
+
+	account, err := h.ledgerService.GetAccount(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, "GetMyAccount", err)
+		return
+	}
+
+	writeJSON(w, r, "GetMyAccount", http.StatusOK, account)
+}
+
+func (h *Handler) recordPayment(w http.ResponseWriter, r *http.Request) {
+	h.recordCredit(w, r, "RecordPayment", domain.LedgerPayment)
+}
+
+func (h *Handler) recordWaiver(w http.ResponseWriter, r *http.Request) {
+	h.recordCredit(w, r, "RecordWaiver", domain.LedgerWaiver)
+}
+
+func (h *Handler) recordCredit(w http.ResponseWriter, r *http.Request, name, kind string) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	var inp domain.CreditInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(kind); err != nil {
+		writeError(w, r, name, err)
+		return
+	}
+
+	var entry domain.LedgerEntry
+	if kind == domain.LedgerWaiver {
+		entry, err = h.ledgerService.Waive(r.Context(), id, inp)
+	} else {
+		entry, err = h.ledgerService.Pay(r.Context(), id, inp)
+	}
+	if err != nil {
+		writeError(w, r, name, err)
+		return
+	}
+
+	writeJSON(w, r, name, http.StatusCreated, entry)
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) checkout(w http.ResponseWriter, r *http.Request) {
+	var inp domain.CheckoutInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "Checkout", err)
+		return
+	}
+
+	loan, err := h.loansService.Checkout(r.Context(), inp)
+	if err != nil {
+		writeError(w, r, "Checkout", err)
+		return
+	}
+
+	writeJSON(w, r, "Checkout", http.StatusCreated, loan)
+}
+
+func (h *Handler) returnLoan(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	loan, err := h.loansService.Return(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "ReturnLoan", err)
+		return
+	}
+
+	writeJSON(w, r, "ReturnLoan", http.StatusOK, loan)
+}
+
+func (h *Handler) renewLoan(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	loan, err := h.loansService.Renew(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "RenewLoan", err)
+		return
+	}
+
+	writeJSON(w, r, "RenewLoan", http.StatusOK, loan)
+}
+
+func (h *Handler) getLoanByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	loan, err := h.loansService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetLoanByID", err)
+		return
+	}
+
+	writeJSON(w, r, "GetLoanByID", http.StatusOK, loan)
+}
+
+func (h *Handler) getAllLoans(w http.ResponseWriter, r *http.Request) {
+	filter, err := loanFilterFromQuery(r)
+	if err != nil {
+		writeError(w, r, "GetAllLoans", err)
+		return
+	}
+
+	loans, err := h.loansService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "GetAllLoans", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAllLoans", http.StatusOK, loans)
+}
+
+// getMyLoans lists the open loans of the current user, or all of them
+// with history=true.
+func (h *Handler) getMyLoans(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "GetMyLoans", domain.ErrUnauthorized)
+		return
+	}
+
+	history, err := optionalBool(r, "history")
+	if err != nil {
+		badRequest(w, r, "history", err)
+		return
+	}
+
+	loans, err := h.loansService.GetAll(r.Context(), domain.LoanFilter{UserID: &userID, Open: !history})
+	if err != nil {
+		writeError(w, r, "GetMyLoans", err)
+		return
+	}
+
+	writeJSON(w, r, "GetMyLoans", http.StatusOK, loans)
+}
+
+func (h *Handler) renewMyLoan(w http.ResponseWriter, r *http.Request) {
+	userID, ok := domain.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, r, "RenewMyLoan", domain.ErrUnauthorized)
+		return
+	}
+
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	loan, err := h.loansService.RenewOwn(r.Context(), userID, id)
+	if err != nil {
+		writeError(w, r, "RenewMyLoan", err)
+		return
+	}
+
+	writeJSON(w, r, "RenewMyLoan", http.StatusOK, loan)
+}
+
+func loanFilterFromQuery(r *http.Request) (domain.LoanFilter, error) {
+	var (
+		filter domain.LoanFilter
+		err    error
+		q      = r.URL.Query()
+	)
+
+	if filter.UserID, err = optionalInt(q, "user_id"); err != nil {
+		return filter, err
+	}
+	if filter.ItemID, err = optionalInt(q, "item_id"); err != nil {
+		return filter, err
+	}
+	if filter.Open, err = optionalBool(r, "open"); err != nil {
+		return filter, invalidParam("open", "must be a boolean")
+	}
+	if filter.Overdue, err = optionalBool(r, "overdue"); err != nil {
+		return filter, invalidParam("overdue", "must be a boolean")
+	}
+
+	return filter, nil
+}
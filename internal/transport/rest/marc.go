@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"encoding/xml"
+	"lib/internal/bookio"
+	"lib/internal/domain"
+	"lib/pkg/marc"
+	"net/http"
+)
+
+// Bibliographic representations of a book, besides JSON.
+const (
+	mediaMARC       = "application/marc"
+	mediaMARCXML    = "application/marcxml+xml"
+	mediaDublinCore = "application/oai_dc+xml"
+)
+
+// writeBookRecord renders book as a MARC 21 record, in ISO 2709 or
+// MARCXML, or as an oai_dc Dublin Core record.
+func writeBookRecord(w http.ResponseWriter, r *http.Request, handlerName, mediaType string, book domain.Book) {
+	rec := bookio.MARCRecord(book)
+
+	var (
+		body []byte
+		err  error
+	)
+
+	switch mediaType {
+	case mediaMARC:
+		body, err = marc.Marshal(rec)
+	case mediaMARCXML:
+		rec.XMLName = xml.Name{Space: marc.Namespace, Local: "record"}
+		body, err = xml.Marshal(rec)
+	case mediaDublinCore:
+		body, err = xml.Marshal(marc.ToDublinCore(rec))
+	}
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	if mediaType != mediaMARC {
+		body = append([]byte(xml.Header), body...)
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Write(body)
+}
@@ -1,18 +1,17 @@
 package rest
 
 import (
-	"context"
 	"errors"
+	"fmt"
+	"lib/internal/domain"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/gorilla/mux"
 	log "github.com/sirupsen/logrus"
-)
-
-type CtxValue int
-
-const (
-	ctxUserID CtxValue = iota
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -25,22 +24,64 @@ func loggingMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// traceMiddleware echoes the current span context back to the caller as a
+// W3C traceparent header.
+func traceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(w.Header()))
+		next.ServeHTTP(w, r)
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// metricsMiddleware labels requests with the route template rather than the
+// raw path, so /books/1 and /books/2 share a series.
+func (h *Handler) metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if tpl, err := current.GetPathTemplate(); err == nil {
+				route = tpl
+			}
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		h.metrics.ObserveRequest(r.Method, route, rec.status, time.Since(start))
+	})
+}
+
 func (h *Handler) authMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		token, err := getTokenFromRequest(r)
 		if err != nil {
 			log.Error("authMiddleware", err)
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, r, "authMiddleware", fmt.Errorf("%w: %s", domain.ErrUnauthorized, err))
 			return
 		}
 
 		userId, err := h.usersService.ParseToken(r.Context(), token)
 		if err != nil {
 			log.Error("authMiddleware", err)
-			w.WriteHeader(http.StatusUnauthorized)
+			writeError(w, r, "authMiddleware", fmt.Errorf("%w: %s", domain.ErrUnauthorized, err))
 			return
 		}
-		ctx := context.WithValue(r.Context(), ctxUserID, userId)
+		ctx := domain.WithUserID(r.Context(), userId)
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
@@ -65,3 +106,31 @@ func getTokenFromRequest(r *http.Request) (string, error) {
 
 	return headerParts[1], nil
 }
+
+// adminMiddleware must run after authMiddleware.
+func (h *Handler) adminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, ok := domain.UserIDFromContext(r.Context())
+		if !ok {
+			writeError(w, r, "adminMiddleware", domain.ErrUnauthorized)
+			return
+		}
+
+		user, err := h.usersService.GetByID(r.Context(), userId)
+		if err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				err = domain.ErrUnauthorized
+			}
+
+			writeError(w, r, "adminMiddleware", err)
+			return
+		}
+
+		if !user.IsAdmin() {
+			writeError(w, r, "adminMiddleware", domain.ErrForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"lib/internal/domain"
+	"lib/internal/opds"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// opdsPageSize is the number of books on one page of an acquisition feed.
+	opdsPageSize = 25
+	// maxOPDSPage bounds the page parameter, and with it the offset.
+	maxOPDSPage = 10000
+)
+
+const mediaAtom = "application/atom+xml"
+
+func (h *Handler) getOPDSRoot(w http.ResponseWriter, r *http.Request) {
+	base := h.baseURL(r)
+
+	if negotiate(r, mediaAtom, opds.TypeJSON) == opds.TypeJSON {
+		writeOPDSJSON(w, r, "GetOPDSRoot", opds.NavigationJSON(base))
+		return
+	}
+
+	writeOPDSAtom(w, r, "GetOPDSRoot", opds.TypeNavigation, opds.Navigation(base, time.Now()))
+}
+
+// getOPDSSection returns the acquisition feed of section.
+func (h *Handler) getOPDSSection(section opds.Section) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.writeOPDSPage(w, r, "GetOPDSSection", section.Path, section.Title, nil, section.Filter)
+	}
+}
+
+func (h *Handler) searchOPDS(w http.ResponseWriter, r *http.Request) {
+	terms := strings.TrimSpace(r.URL.Query().Get("q"))
+	if terms == "" {
+		writeError(w, r, "SearchOPDS", invalidParam("q", "is required"))
+		return
+	}
+
+	h.writeOPDSPage(w, r, "SearchOPDS", opds.SearchPath, "Search results for "+strconv.Quote(terms),
+		url.Values{"q": {terms}}, domain.BookFilter{Query: terms})
+}
+
+func (h *Handler) getOPDSOpenSearch(w http.ResponseWriter, r *http.Request) {
+	body, err := xml.Marshal(opds.OpenSearch(h.baseURL(r)))
+	if err != nil {
+		writeError(w, r, "GetOPDSOpenSearch", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", opds.TypeOpenSearch)
+	w.Write(append([]byte(xml.Header), body...))
+}
+
+// writeOPDSPage writes the page of the acquisition feed at path requested
+// by the page parameter. One book more than fits is fetched to tell whether
+// a next page exists.
+func (h *Handler) writeOPDSPage(w http.ResponseWriter, r *http.Request, handlerName, path, title string, query url.Values, filter domain.BookFilter) {
+	number, err := pageNumber(r.URL.Query())
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	filter.Limit = opdsPageSize + 1
+	filter.Offset = (number - 1) * opdsPageSize
+
+	books, err := h.booksService.GetAll(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	page := opds.Page{
+		Path:    path,
+		Title:   title,
+		Query:   query,
+		Books:   books,
+		Number:  number,
+		Size:    opdsPageSize,
+		Updated: time.Now(),
+	}
+	if len(books) > opdsPageSize {
+		page.Books, page.HasNext = books[:opdsPageSize], true
+	}
+
+	base := h.baseURL(r)
+
+	if negotiate(r, mediaAtom, opds.TypeJSON) == opds.TypeJSON {
+		writeOPDSJSON(w, r, handlerName, opds.AcquisitionJSON(base, page))
+		return
+	}
+
+	writeOPDSAtom(w, r, handlerName, opds.TypeAcquisition, opds.Acquisition(base, page))
+}
+
+func writeOPDSAtom(w http.ResponseWriter, r *http.Request, handlerName, mediaType string, feed opds.Feed) {
+	body, err := xml.Marshal(feed)
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Add("Vary", "Accept")
+	w.Write(append([]byte(xml.Header), body...))
+}
+
+func writeOPDSJSON(w http.ResponseWriter, r *http.Request, handlerName string, feed opds.JSONFeed) {
+	body, err := json.Marshal(feed)
+	if err != nil {
+		writeError(w, r, handlerName, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", opds.TypeJSON)
+	w.Header().Add("Vary", "Accept")
+	w.Write(body)
+}
+
+// pageNumber reads the 1-based page parameter, which defaults to 1.
+func pageNumber(q url.Values) (int, error) {
+	v := q.Get("page")
+	if v == "" {
+		return 1, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxOPDSPage {
+		return 0, invalidParam("page", "must be an integer between 1 and "+strconv.Itoa(maxOPDSPage))
+	}
+
+	return n, nil
+}
+
+// baseURL returns the scheme and host feeds link to, since OPDS clients
+// expect absolute links. It is the configured public URL or else the one
+// the request reached the server at. Forwarding headers are not trusted.
+func (h *Handler) baseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
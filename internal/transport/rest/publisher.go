@@ -0,0 +1,94 @@
+package rest
+
+import (
+	"lib/internal/domain"
+	"net/http"
+)
+
+func (h *Handler) createPublisher(w http.ResponseWriter, r *http.Request) {
+	var inp domain.PublisherInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "CreatePublisher", err)
+		return
+	}
+
+	id, err := h.publishersService.Create(r.Context(), inp)
+	if err != nil {
+		writeError(w, r, "CreatePublisher", err)
+		return
+	}
+
+	writeJSON(w, r, "CreatePublisher", http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (h *Handler) getAllPublishers(w http.ResponseWriter, r *http.Request) {
+	publishers, err := h.publishersService.GetAll(r.Context())
+	if err != nil {
+		writeError(w, r, "GetAllPublishers", err)
+		return
+	}
+
+	writeJSON(w, r, "GetAllPublishers", http.StatusOK, publishers)
+}
+
+func (h *Handler) getPublisherByID(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	publisher, err := h.publishersService.GetByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetPublisherByID", err)
+		return
+	}
+
+	writeJSON(w, r, "GetPublisherByID", http.StatusOK, publisher)
+}
+
+func (h *Handler) updatePublisher(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	var inp domain.PublisherInput
+	if err := decodeJSON(w, r, &inp); err != nil {
+		badRequest(w, r, "body", err)
+		return
+	}
+
+	inp.Normalize()
+	if err := inp.Validate(); err != nil {
+		writeError(w, r, "UpdatePublisher", err)
+		return
+	}
+
+	if err := h.publishersService.Update(r.Context(), id, inp); err != nil {
+		writeError(w, r, "UpdatePublisher", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) deletePublisher(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	if err := h.publishersService.Delete(r.Context(), id); err != nil {
+		writeError(w, r, "DeletePublisher", err)
+		return
+	}
+}
@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) getBookRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	revisions, err := h.booksService.GetRevisions(r.Context(), id)
+	if err != nil {
+		writeError(w, r, "GetBookRevisions", err)
+		return
+	}
+
+	response, err := json.Marshal(revisions)
+	if err != nil {
+		writeError(w, r, "GetBookRevisions", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func (h *Handler) getBookRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	revision, err := getRevisionFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "revision", err)
+		return
+	}
+
+	rev, err := h.booksService.GetRevision(r.Context(), id, revision)
+	if err != nil {
+		writeError(w, r, "GetBookRevision", err)
+		return
+	}
+
+	response, err := json.Marshal(rev)
+	if err != nil {
+		writeError(w, r, "GetBookRevision", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Write(response)
+}
+
+func (h *Handler) revertBook(w http.ResponseWriter, r *http.Request) {
+	id, err := getIdFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "id", err)
+		return
+	}
+
+	revision, err := getRevisionFromRequest(r)
+	if err != nil {
+		badRequest(w, r, "revision", err)
+		return
+	}
+
+	version, err := h.ifMatchBookVersion(r, id)
+	if err != nil {
+		writeError(w, r, "RevertBook", err)
+		return
+	}
+
+	newVersion, err := h.booksService.Revert(r.Context(), id, revision, version)
+	if err != nil {
+		writeError(w, r, "RevertBook", err)
+		return
+	}
+
+	w.Header().Set("ETag", versionETag(newVersion))
+	w.WriteHeader(http.StatusOK)
+}
+
+func getRevisionFromRequest(r *http.Request) (int, error) {
+	revision, err := strconv.Atoi(mux.Vars(r)["revision"])
+	if err != nil {
+		return 0, err
+	}
+
+	if revision <= 0 {
+		return 0, errors.New("revision can't be 0")
+	}
+
+	return revision, nil
+}
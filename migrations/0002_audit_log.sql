@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq       BIGINT PRIMARY KEY,
+    entity    VARCHAR(32) NOT NULL,
+    action    VARCHAR(32) NOT NULL,
+    entity_id BIGINT NOT NULL,
+    actor_id  BIGINT NOT NULL DEFAULT 0,
+    timestamp TIMESTAMPTZ NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash      CHAR(64) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    seq        BIGINT PRIMARY KEY REFERENCES audit_log (seq),
+    hash       CHAR(64) NOT NULL,
+    signature  CHAR(64) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
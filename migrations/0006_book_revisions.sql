@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS book_revisions (
+    id         BIGSERIAL PRIMARY KEY,
+    book_id    BIGINT NOT NULL,
+    version    INT NOT NULL,
+    action     VARCHAR(32) NOT NULL,
+    snapshot   JSONB NOT NULL,
+    diff       JSONB NOT NULL,
+    actor_id   BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (book_id, version)
+);
+
+-- Seed the history with the current state of every existing book.
+INSERT INTO book_revisions (book_id, version, action, snapshot, diff)
+SELECT id, version, 'CREATE',
+       jsonb_build_object(
+           'id', id,
+           'name', name,
+           'author', author,
+           'publisher', to_char(publisher, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+           'rating', rating,
+           'version', version
+       ),
+       '{}'::jsonb
+FROM books
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS authors (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    bio        TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS authors_name_key ON authors (lower(name));
+
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id   BIGINT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    author_id BIGINT NOT NULL REFERENCES authors (id) ON DELETE RESTRICT,
+    role      VARCHAR(32) NOT NULL DEFAULT 'author' CHECK (role IN ('author', 'editor', 'translator')),
+    position  INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS book_authors_author_idx ON book_authors (author_id);
+
+-- Backfill from the free-text books.author column, one author per distinct
+-- (case-insensitive) name.
+INSERT INTO authors (name)
+SELECT DISTINCT ON (lower(btrim(author))) btrim(author)
+FROM books
+WHERE btrim(author) <> ''
+ORDER BY lower(btrim(author)), btrim(author)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO book_authors (book_id, author_id, role, position)
+SELECT b.id, a.id, 'author', 0
+FROM books b
+JOIN authors a ON lower(a.name) = lower(btrim(b.author))
+ON CONFLICT DO NOTHING;
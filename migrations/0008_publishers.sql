@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS publishers (
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    country    CHAR(2) NOT NULL DEFAULT '',
+    website    VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS publishers_name_key ON publishers (lower(name));
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS publisher_id BIGINT REFERENCES publishers (id) ON DELETE RESTRICT;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS published_on DATE;
+ALTER TABLE books ADD COLUMN IF NOT EXISTS published_precision VARCHAR(5)
+    CHECK (published_precision IN ('year', 'month', 'day'));
+
+-- books.publisher held the publication date, not the publisher. It also
+-- defaulted to now() and the API stored the zero time when none was given.
+-- Publication dates are whole days, so only midnight values other
+-- than the zero time are taken as real; the rest leave published_on empty.
+-- Every original value is kept in books_legacy_publisher.
+CREATE TABLE IF NOT EXISTS books_legacy_publisher (
+    book_id   BIGINT PRIMARY KEY,
+    publisher TIMESTAMP NOT NULL
+);
+
+INSERT INTO books_legacy_publisher (book_id, publisher)
+SELECT id, publisher FROM books
+ON CONFLICT DO NOTHING;
+
+UPDATE books SET published_on = publisher::date, published_precision = 'day'
+WHERE publisher = date_trunc('day', publisher) AND publisher > '0001-01-01';
+
+ALTER TABLE books DROP COLUMN IF EXISTS publisher;
@@ -0,0 +1,4 @@
+-- ISBNs are stored in their 13-digit form; the 10-digit form is derived.
+ALTER TABLE books ADD COLUMN IF NOT EXISTS isbn CHAR(13);
+
+CREATE UNIQUE INDEX IF NOT EXISTS books_isbn_key ON books (isbn);
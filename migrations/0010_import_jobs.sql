@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id          BIGSERIAL PRIMARY KEY,
+    status      VARCHAR(16) NOT NULL DEFAULT 'pending',
+    format      VARCHAR(16) NOT NULL,
+    dry_run     BOOLEAN NOT NULL DEFAULT false,
+    actor_id    BIGINT NOT NULL DEFAULT 0,
+    report      JSONB,
+    error       TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
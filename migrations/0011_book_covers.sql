@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS book_covers (
+    book_id      BIGINT PRIMARY KEY REFERENCES books (id) ON DELETE CASCADE,
+    content_type VARCHAR(32) NOT NULL,
+    width        INTEGER NOT NULL,
+    height       INTEGER NOT NULL,
+    size         BIGINT NOT NULL,
+    checksum     CHAR(64) NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
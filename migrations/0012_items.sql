@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS items (
+    id          BIGSERIAL PRIMARY KEY,
+    book_id     BIGINT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    barcode     VARCHAR(48) NOT NULL,
+    shelf       VARCHAR(64) NOT NULL DEFAULT '',
+    condition   VARCHAR(16) NOT NULL DEFAULT 'good'
+        CHECK (condition IN ('new', 'good', 'fair', 'poor', 'damaged')),
+    acquired_on DATE,
+    status      VARCHAR(16) NOT NULL DEFAULT 'available'
+        CHECK (status IN ('available', 'on_loan', 'lost', 'in_repair')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS items_barcode_key ON items (barcode);
+CREATE INDEX IF NOT EXISTS items_book_id_idx ON items (book_id);
@@ -0,0 +1,17 @@
+ALTER TABLE items ADD COLUMN IF NOT EXISTS type VARCHAR(16) NOT NULL DEFAULT 'book'
+    CHECK (type IN ('book', 'reference', 'periodical', 'media'));
+
+CREATE TABLE IF NOT EXISTS loans (
+    id             BIGSERIAL PRIMARY KEY,
+    item_id        BIGINT NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+    user_id        INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    checked_out_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    due_at         TIMESTAMPTZ NOT NULL,
+    returned_at    TIMESTAMPTZ,
+    renewals       INT NOT NULL DEFAULT 0
+);
+
+-- An item is on at most one open loan.
+CREATE UNIQUE INDEX IF NOT EXISTS loans_item_open_key ON loans (item_id) WHERE returned_at IS NULL;
+CREATE INDEX IF NOT EXISTS loans_user_id_idx ON loans (user_id, checked_out_at DESC);
+CREATE INDEX IF NOT EXISTS loans_due_at_idx ON loans (due_at) WHERE returned_at IS NULL;
@@ -0,0 +1,25 @@
+-- Items allocated to a hold wait on the hold shelf until picked up.
+ALTER TABLE items DROP CONSTRAINT IF EXISTS items_status_check;
+ALTER TABLE items ADD CONSTRAINT items_status_check
+    CHECK (status IN ('available', 'on_loan', 'on_hold', 'lost', 'in_repair'));
+
+CREATE TABLE IF NOT EXISTS holds (
+    id         BIGSERIAL PRIMARY KEY,
+    book_id    BIGINT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    user_id    INT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status     VARCHAR(16) NOT NULL DEFAULT 'waiting'
+        CHECK (status IN ('waiting', 'ready', 'fulfilled', 'expired', 'cancelled')),
+    item_id    BIGINT REFERENCES items (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ready_at   TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ,
+    closed_at  TIMESTAMPTZ
+);
+
+-- A user holds a title at most once at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS holds_user_book_active_key ON holds (book_id, user_id)
+    WHERE status IN ('waiting', 'ready');
+CREATE INDEX IF NOT EXISTS holds_queue_idx ON holds (book_id, created_at, id) WHERE status = 'waiting';
+CREATE INDEX IF NOT EXISTS holds_item_id_idx ON holds (item_id) WHERE status = 'ready';
+CREATE INDEX IF NOT EXISTS holds_expires_at_idx ON holds (expires_at) WHERE status = 'ready';
+CREATE INDEX IF NOT EXISTS holds_user_id_idx ON holds (user_id, created_at DESC);
@@ -0,0 +1,33 @@
+-- The patron ledger. Amounts are in cents: charges are positive and
+-- credits negative, so a balance is the sum of a user's entries.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     INT NOT NULL REFERENCES users (id),
+    kind        VARCHAR(16) NOT NULL CHECK (kind IN ('fine', 'payment', 'waiver')),
+    amount      BIGINT NOT NULL,
+    -- Fines name the loan and the overdue day they charge for. Loans may
+    -- be deleted with their item, the ledger keeps the reference.
+    loan_id     BIGINT,
+    accrued_on  DATE,
+    note        TEXT NOT NULL DEFAULT '',
+    recorded_by INT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT ledger_entries_amount_check CHECK ((kind = 'fine') = (amount > 0) AND amount <> 0),
+    CONSTRAINT ledger_entries_fine_check CHECK ((kind = 'fine') = (loan_id IS NOT NULL AND accrued_on IS NOT NULL))
+);
+
+-- A loan is fined at most once per overdue day, which makes accrual
+-- idempotent.
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_entries_fine_key ON ledger_entries (loan_id, accrued_on) WHERE kind = 'fine';
+CREATE INDEX IF NOT EXISTS ledger_entries_user_id_idx ON ledger_entries (user_id, id);
+
+-- Corrections are new entries; existing ones are never changed.
+CREATE OR REPLACE FUNCTION ledger_entries_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger entries are append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS ledger_entries_append_only ON ledger_entries;
+CREATE TRIGGER ledger_entries_append_only BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE PROCEDURE ledger_entries_append_only();
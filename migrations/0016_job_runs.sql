@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+    id          BIGSERIAL PRIMARY KEY,
+    job         VARCHAR(64) NOT NULL,
+    instance    VARCHAR(255) NOT NULL DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    result      VARCHAR(16) CHECK (result IN ('success', 'failure', 'timeout')),
+    error       TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_idx ON job_runs (job, started_at DESC);
+
+-- Rating aggregates of the books outside the trash, by author and by
+-- publisher. Unrated books (rating 0) are counted but not averaged. The
+-- views are refreshed by a scheduled job.
+CREATE MATERIALIZED VIEW IF NOT EXISTS author_rating_stats AS
+SELECT ba.author_id,
+       count(DISTINCT b.id) AS books,
+       count(DISTINCT b.id) FILTER (WHERE b.rating > 0) AS rated,
+       coalesce(round(avg(b.rating) FILTER (WHERE b.rating > 0), 2), 0) AS average
+FROM book_authors ba
+JOIN books b ON b.id = ba.book_id AND b.deleted_at IS NULL
+GROUP BY ba.author_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS author_rating_stats_author_id_key ON author_rating_stats (author_id);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS publisher_rating_stats AS
+SELECT b.publisher_id,
+       count(*) AS books,
+       count(*) FILTER (WHERE b.rating > 0) AS rated,
+       coalesce(round(avg(b.rating) FILTER (WHERE b.rating > 0), 2), 0) AS average
+FROM books b
+WHERE b.deleted_at IS NULL AND b.publisher_id IS NOT NULL
+GROUP BY b.publisher_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS publisher_rating_stats_publisher_id_key ON publisher_rating_stats (publisher_id);
+
+-- Expired refresh sessions are purged by a scheduled job.
+CREATE INDEX IF NOT EXISTS refresh_tokens_expires_at_idx ON refresh_tokens (expires_at);
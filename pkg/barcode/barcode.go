@@ -0,0 +1,85 @@
+// Package barcode validates the item barcodes library scanners read:
+// EAN-13 and Code 128.
+package barcode
+
+import "errors"
+
+// Symbologies.
+const (
+	EAN13   = "ean13"
+	Code128 = "code128"
+)
+
+// MaxLength is the longest Code 128 value accepted. Longer symbols do not
+// fit on item labels.
+const MaxLength = 48
+
+var (
+	ErrEmpty      = errors.New("barcode: empty")
+	ErrLength     = errors.New("barcode: too long")
+	ErrCharacter  = errors.New("barcode: character cannot be encoded in Code 128")
+	ErrCheckDigit = errors.New("barcode: EAN-13 check digit mismatch")
+)
+
+// Parse validates s and returns its symbology. Thirteen digits are read as
+// EAN-13 and must carry a correct check digit; anything else is read as
+// Code 128. A scanner verifies the Code 128 check symbol itself and does
+// not transmit it, so only the data characters are checked.
+func Parse(s string) (string, error) {
+	if len(s) == 13 && digits(s) {
+		return EAN13, ValidateEAN13(s)
+	}
+
+	return Code128, ValidateCode128(s)
+}
+
+// ValidateEAN13 reports whether s is thirteen digits ending in the
+// modulo 10 check digit of the first twelve.
+func ValidateEAN13(s string) error {
+	if len(s) != 13 || !digits(s) {
+		return ErrCharacter
+	}
+
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(s[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	if int(s[12]-'0') != (10-sum%10)%10 {
+		return ErrCheckDigit
+	}
+
+	return nil
+}
+
+// ValidateCode128 reports whether s can be encoded in Code 128, which
+// covers the 128 ASCII characters across its code sets.
+func ValidateCode128(s string) error {
+	if s == "" {
+		return ErrEmpty
+	}
+	if len(s) > MaxLength {
+		return ErrLength
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] > 127 {
+			return ErrCharacter
+		}
+	}
+
+	return nil
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
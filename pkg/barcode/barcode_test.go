@@ -0,0 +1,37 @@
+package barcode
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		symbology string
+		err       error
+	}{
+		{"ean13", "4006381333931", EAN13, nil},
+		{"ean13 isbn", "9780306406157", EAN13, nil},
+		{"ean13 zero check", "5901234123457", EAN13, nil},
+		{"ean13 bad check", "4006381333932", EAN13, ErrCheckDigit},
+		{"code128 alphanumeric", "LIB-000123", Code128, nil},
+		{"code128 digits", "31234000012345", Code128, nil},
+		{"code128 twelve digits", "400638133393", Code128, nil},
+		{"code128 control character", "A\tB", Code128, nil},
+		{"empty", "", Code128, ErrEmpty},
+		{"too long", strings.Repeat("A", MaxLength+1), Code128, ErrLength},
+		{"non ascii", "BOOK-é", Code128, ErrCharacter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbology, err := Parse(tt.input)
+			if symbology != tt.symbology || !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) = %q, %v, want %q, %v", tt.input, symbology, err, tt.symbology, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Package blob stores binary objects under slash-separated keys, either in
+// a local directory or in an S3-compatible object store.
+package blob
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned when no object is stored under a key.
+var ErrNotFound = errors.New("blob: not found")
+
+// ErrKey is returned for keys that are empty, absolute or contain empty,
+// "." or ".." segments.
+var ErrKey = errors.New("blob: invalid key")
+
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return ErrKey
+	}
+
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return ErrKey
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"1,,2 * * * *",
+		"@every 5m",
+	}
+
+	for _, expr := range tests {
+		if _, err := Parse(expr); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Parse(%q) error = %v, want %v", expr, err, ErrSyntax)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		expr string
+		from string
+		want string
+	}{
+		{"* * * * *", "2026-10-19 10:00:00", "2026-10-19 10:01:00"},
+		{"* * * * *", "2026-10-19 10:00:59.9", "2026-10-19 10:01:00"},
+		{"*/15 * * * *", "2026-10-19 10:07:00", "2026-10-19 10:15:00"},
+		{"*/15 * * * *", "2026-10-19 10:45:00", "2026-10-19 11:00:00"},
+		{"5/20 * * * *", "2026-10-19 10:26:00", "2026-10-19 10:45:00"},
+		{"0 3 * * *", "2026-10-19 03:00:00", "2026-10-20 03:00:00"},
+		{"30 2 * * *", "2026-10-19 01:00:00", "2026-10-19 02:30:00"},
+		{"0 9-17/4 * * *", "2026-10-19 14:00:00", "2026-10-19 17:00:00"},
+		{"0 0 1,15 * *", "2026-10-02 00:00:00", "2026-10-15 00:00:00"},
+		{"0 0 31 * *", "2026-11-01 00:00:00", "2026-12-31 00:00:00"},
+		{"0 0 29 2 *", "2026-03-01 00:00:00", "2028-02-29 00:00:00"},
+		{"0 12 * jan,jul *", "2026-10-19 00:00:00", "2027-01-01 12:00:00"},
+		{"0 0 * * mon-fri", "2026-10-23 12:00:00", "2026-10-26 00:00:00"},
+		{"0 0 * * 7", "2026-10-19 00:00:00", "2026-10-25 00:00:00"},
+		{"0 0 * * */2", "2026-10-19 00:00:00", "2026-10-20 00:00:00"},
+		// Restricted day of month and day of week match either.
+		{"0 0 13 * fri", "2026-10-19 00:00:00", "2026-10-23 00:00:00"},
+		{"0 0 13 * fri", "2026-10-24 00:00:00", "2026-10-30 00:00:00"},
+		// A day of month starting with "*" leaves day of week to decide.
+		{"0 0 */10 * mon", "2026-10-19 00:00:00", "2026-10-26 00:00:00"},
+		{"@hourly", "2026-10-19 10:30:00", "2026-10-19 11:00:00"},
+		{"@daily", "2026-10-19 10:30:00", "2026-10-20 00:00:00"},
+		{"@weekly", "2026-10-19 10:30:00", "2026-10-25 00:00:00"},
+		{"@monthly", "2026-10-19 10:30:00", "2026-11-01 00:00:00"},
+		{"@yearly", "2026-10-19 10:30:00", "2027-01-01 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr+" from "+tt.from, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := s.Next(parseTime(t, tt.from, time.UTC))
+			if want := parseTime(t, tt.want, time.UTC); !got.Equal(want) {
+				t.Errorf("Next = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	s := MustParse("0 0 30 2 *")
+	if got := s.Next(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next = %s, want the zero time", got)
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 2:30 does not exist on 29 March 2026; the schedule fires the next day.
+	s := MustParse("30 2 * * *")
+	got := s.Next(parseTime(t, "2026-03-29 01:00:00", loc))
+	if want := parseTime(t, "2026-03-30 02:30:00", loc); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+
+	s = MustParse("0 4 * * *")
+	got = s.Next(parseTime(t, "2026-03-29 01:00:00", loc))
+	if want := parseTime(t, "2026-03-29 04:00:00", loc); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func parseTime(t *testing.T, s string, loc *time.Location) time.Time {
+	t.Helper()
+
+	v, err := time.ParseInLocation("2006-01-02 15:04:05", s, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+)
+
+// PingCheck reports whether the connection pool can reach Postgres.
+type PingCheck struct {
+	db *sql.DB
+}
+
+func NewPingCheck(db *sql.DB) *PingCheck {
+	return &PingCheck{db: db}
+}
+
+func (c *PingCheck) Name() string {
+	return "postgres"
+}
+
+func (c *PingCheck) Check(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+// MigrationsCheck fails while any migration from fsys is still unapplied.
+// It only reads schema_migrations, so probes never take DDL locks.
+type MigrationsCheck struct {
+	db   *sql.DB
+	fsys fs.FS
+}
+
+func NewMigrationsCheck(db *sql.DB, fsys fs.FS) *MigrationsCheck {
+	return &MigrationsCheck{db: db, fsys: fsys}
+}
+
+func (c *MigrationsCheck) Name() string {
+	return "migrations"
+}
+
+func (c *MigrationsCheck) Check(ctx context.Context) error {
+	pending, err := pendingMigrations(ctx, c.db, c.fsys)
+	if err != nil {
+		return err
+	}
+
+	if len(pending) > 0 {
+		return fmt.Errorf("%d pending migrations, next %s", len(pending), pending[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"sync"
+)
+
+// AdvisoryLock is a Postgres session-level advisory lock used for leader
+// election: of all processes sharing a database, at most one holds it.
+// The lock lives on a dedicated connection, so it is released by Postgres
+// as soon as the holder goes away.
+type AdvisoryLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLock returns the lock identified by name.
+func NewAdvisoryLock(db *sql.DB, name string) *AdvisoryLock {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+
+	return &AdvisoryLock{
+		db:  db,
+		key: int64(h.Sum64()),
+	}
+}
+
+// TryAcquire takes the lock if it is free and reports whether it is held.
+// It never waits for another holder. A holder whose connection broke is
+// told it lost the lock.
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		discard(l.conn)
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release gives the lock up if it is held.
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	if err != nil {
+		discard(l.conn)
+	} else {
+		l.conn.Close()
+	}
+	l.conn = nil
+
+	return err
+}
+
+// discard closes the underlying connection of conn instead of returning
+// it to the pool, where a lock it may still hold would leak to whichever
+// caller takes it next.
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
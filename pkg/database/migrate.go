@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"sort"
+)
+
+const migrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version    VARCHAR(255) PRIMARY KEY,
+	applied_at TIMESTAMP NOT NULL DEFAULT now()
+)`
+
+// Migrate applies every *.sql file from fsys that is not yet recorded in
+// schema_migrations. Each file runs in its own transaction.
+func Migrate(db *sql.DB, fsys fs.FS) error {
+	pending, err := PendingMigrations(db, fsys)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range pending {
+		query, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(string(query)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version) VALUES ($1)", name); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %w", name, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PendingMigrations returns the names of migrations from fsys that have not
+// been applied yet, sorted in the order Migrate would apply them.
+func PendingMigrations(db *sql.DB, fsys fs.FS) ([]string, error) {
+	if _, err := db.Exec(migrationsTable); err != nil {
+		return nil, err
+	}
+
+	return pendingMigrations(context.Background(), db, fsys)
+}
+
+// pendingMigrations is PendingMigrations without any write. A database
+// without schema_migrations has every migration pending.
+func pendingMigrations(ctx context.Context, db *sql.DB, fsys fs.FS) ([]string, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return names, nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := make([]string, 0)
+	for _, name := range names {
+		if !applied[name] {
+			pending = append(pending, name)
+		}
+	}
+
+	return pending, nil
+}
@@ -3,6 +3,9 @@ package database
 import (
 	"database/sql"
 	"fmt"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 type ConnectionInfo struct {
@@ -15,8 +18,9 @@ type ConnectionInfo struct {
 }
 
 func NewPostgresConnection(info ConnectionInfo) (*sql.DB, error) {
-	db, err := sql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
-		info.Host, info.Port, info.User, info.Password, info.Name, info.SSLMode))
+	db, err := otelsql.Open("postgres", fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+		info.Host, info.Port, info.User, info.Password, info.Name, info.SSLMode),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,89 @@
+// Package imaging scales images down for thumbnails, using only the
+// standard library.
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// Fit scales img down to fit within a size×size box, keeping its aspect
+// ratio. Images that already fit are returned unchanged.
+func Fit(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	if w >= h {
+		w, h = size, max(1, h*size/w)
+	} else {
+		w, h = max(1, w*size/h), size
+	}
+
+	return Resize(img, w, h)
+}
+
+// Resize scales img to w×h with a box filter: every output pixel is the
+// average of the source pixels it covers. It is meant for shrinking;
+// enlarging degrades to nearest neighbour.
+func Resize(img image.Image, w, h int) *image.RGBA {
+	src := toRGBA(img)
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := span(y, h, sh)
+
+		for x := 0; x < w; x++ {
+			x0, x1 := span(x, w, sw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(src.Pix[i])
+					g += uint64(src.Pix[i+1])
+					b += uint64(src.Pix[i+2])
+					a += uint64(src.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// span returns the source range [lo, hi) that output index i of n covers
+// in a source of length size. The range is never empty.
+func span(i, n, size int) (int, int) {
+	lo := i * size / n
+	hi := (i + 1) * size / n
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
+
+// toRGBA returns img as premultiplied RGBA anchored at the origin, so that
+// averaging channels also averages coverage correctly.
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	return rgba
+}
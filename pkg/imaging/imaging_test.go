@@ -0,0 +1,49 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		w, h, size   int
+		wantW, wantH int
+	}{
+		{400, 200, 100, 100, 50},
+		{200, 400, 100, 50, 100},
+		{300, 300, 96, 96, 96},
+		{80, 60, 96, 80, 60},
+		{1000, 1, 100, 100, 1},
+	}
+
+	for _, tt := range tests {
+		img := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+		b := Fit(img, tt.size).Bounds()
+		if b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+			t.Errorf("Fit(%dx%d, %d) = %dx%d, want %dx%d", tt.w, tt.h, tt.size, b.Dx(), b.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestResizeAverages(t *testing.T) {
+	// A 4x4 black and white checkerboard shrinks to uniform grey.
+	img := image.NewGray(image.Rect(10, 10, 14, 14))
+	for y := 10; y < 14; y++ {
+		for x := 10; x < 14; x++ {
+			if (x+y)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	dst := Resize(img, 2, 2)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			if got := dst.RGBAAt(x, y); got != (color.RGBA{127, 127, 127, 255}) {
+				t.Errorf("pixel (%d,%d) = %v, want grey", x, y, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package isbn validates International Standard Book Numbers and converts
+// between their 10- and 13-digit forms.
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrLength     = errors.New("isbn: must have 10 or 13 digits")
+	ErrCharacter  = errors.New("isbn: invalid character")
+	ErrCheckDigit = errors.New("isbn: check digit mismatch")
+	ErrNoISBN10   = errors.New("isbn: only 978-prefixed ISBN-13 have an ISBN-10 form")
+)
+
+// Clean strips hyphens and spaces and upper-cases a trailing x. It does not
+// validate.
+func Clean(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '-' || r == ' ':
+		case r == 'x':
+			b.WriteRune('X')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Validate reports whether s, after Clean, is a well-formed ISBN-10 or
+// ISBN-13 with a correct check digit.
+func Validate(s string) error {
+	s = Clean(s)
+
+	switch len(s) {
+	case 10:
+		return validate10(s)
+	case 13:
+		return validate13(s)
+	default:
+		return ErrLength
+	}
+}
+
+// To13 returns the ISBN-13 form of s, which may be either an ISBN-10 or an
+// ISBN-13.
+func To13(s string) (string, error) {
+	s = Clean(s)
+	if err := Validate(s); err != nil {
+		return "", err
+	}
+
+	if len(s) == 13 {
+		return s, nil
+	}
+
+	body := "978" + s[:9]
+	return body + string(checkDigit13(body)), nil
+}
+
+// To10 returns the ISBN-10 form of s. ISBN-13 outside the 978 prefix have
+// none and yield ErrNoISBN10.
+func To10(s string) (string, error) {
+	s = Clean(s)
+	if err := Validate(s); err != nil {
+		return "", err
+	}
+
+	if len(s) == 10 {
+		return s, nil
+	}
+
+	if !strings.HasPrefix(s, "978") {
+		return "", ErrNoISBN10
+	}
+
+	body := s[3:12]
+	return body + string(checkDigit10(body)), nil
+}
+
+func validate10(s string) error {
+	for i := 0; i < 9; i++ {
+		if !isDigit(s[i]) {
+			return ErrCharacter
+		}
+	}
+	if !isDigit(s[9]) && s[9] != 'X' {
+		return ErrCharacter
+	}
+
+	if checkDigit10(s[:9]) != s[9] {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+func validate13(s string) error {
+	for i := 0; i < 13; i++ {
+		if !isDigit(s[i]) {
+			return ErrCharacter
+		}
+	}
+
+	if checkDigit13(s[:12]) != s[12] {
+		return ErrCheckDigit
+	}
+	return nil
+}
+
+// checkDigit10 computes the mod-11 check digit of nine digits.
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += (10 - i) * int(body[i]-'0')
+	}
+
+	switch d := (11 - sum%11) % 11; d {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + d)
+	}
+}
+
+// checkDigit13 computes the mod-10 check digit of twelve digits weighted
+// alternately by 1 and 3.
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		w := 1
+		if i%2 == 1 {
+			w = 3
+		}
+		sum += w * int(body[i]-'0')
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
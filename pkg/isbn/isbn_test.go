@@ -0,0 +1,98 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{"isbn10", "0306406152", nil},
+		{"isbn10 hyphens", "0-306-40615-2", nil},
+		{"isbn10 check x", "0-8044-2957-X", nil},
+		{"isbn10 lower x", "080442957x", nil},
+		{"isbn13", "9780306406157", nil},
+		{"isbn13 spaces", "978 0 306 40615 7", nil},
+		{"isbn13 979", "979-10-90636-07-1", nil},
+		{"empty", "", ErrLength},
+		{"too short", "030640615", ErrLength},
+		{"eleven digits", "03064061522", ErrLength},
+		{"isbn10 bad check", "0306406153", ErrCheckDigit},
+		{"isbn13 bad check", "9780306406158", ErrCheckDigit},
+		{"isbn10 x in body", "03064X6152", ErrCharacter},
+		{"isbn13 with x", "978030640615X", ErrCharacter},
+		{"letters", "abcdefghij", ErrCharacter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.input); !errors.Is(err, tt.err) {
+				t.Fatalf("Validate(%q) = %v, want %v", tt.input, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTo13(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"0-306-40615-2", "9780306406157", nil},
+		{"080442957X", "9780804429573", nil},
+		{"9780306406157", "9780306406157", nil},
+		{"979-10-90636-07-1", "9791090636071", nil},
+		{"0306406153", "", ErrCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := To13(tt.input)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("To13(%q) = %q, %v, want %q, %v", tt.input, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		err   error
+	}{
+		{"978-0-306-40615-7", "0306406152", nil},
+		{"9780804429573", "080442957X", nil},
+		{"0306406152", "0306406152", nil},
+		{"9791090636071", "", ErrNoISBN10},
+		{"9780306406158", "", ErrCheckDigit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := To10(tt.input)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Fatalf("To10(%q) = %q, %v, want %q, %v", tt.input, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, isbn10 := range []string{"0306406152", "080442957X", "0131103628", "1566199093"} {
+		isbn13, err := To13(isbn10)
+		if err != nil {
+			t.Fatalf("To13(%q): %v", isbn10, err)
+		}
+
+		back, err := To10(isbn13)
+		if err != nil || back != isbn10 {
+			t.Fatalf("To10(To13(%q)) = %q, %v", isbn10, back, err)
+		}
+	}
+}
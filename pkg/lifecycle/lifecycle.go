@@ -0,0 +1,131 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Exit codes returned by Run.
+const (
+	ExitOK            = 0
+	ExitServeError    = 1
+	ExitShutdownError = 2
+	ExitStartupError  = 3
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager stops application components in the reverse order they were
+// started once a termination signal arrives or the main server fails.
+type Manager struct {
+	timeout time.Duration
+
+	mu    sync.Mutex
+	hooks []hook
+
+	stopping chan struct{}
+	once     sync.Once
+}
+
+func NewManager(timeout time.Duration) *Manager {
+	return &Manager{
+		timeout:  timeout,
+		stopping: make(chan struct{}),
+	}
+}
+
+// OnShutdown registers fn to run during shutdown. Call it right after the
+// component has been started: hooks run in reverse registration order.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// OnClose is OnShutdown for components that only expose Close.
+func (m *Manager) OnClose(name string, fn func() error) {
+	m.OnShutdown(name, func(context.Context) error {
+		return fn()
+	})
+}
+
+// Stopping is closed as soon as shutdown begins.
+func (m *Manager) Stopping() <-chan struct{} {
+	return m.stopping
+}
+
+// Run calls serve in the background and blocks until SIGINT/SIGTERM is
+// received or serve returns. It then runs the shutdown hooks within the
+// configured timeout and returns the process exit code.
+func (m *Manager) Run(serve func() error) int {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve()
+	}()
+
+	code := ExitOK
+	select {
+	case s := <-sig:
+		log.WithField("signal", s.String()).Info("shutting down")
+	case err := <-serveErr:
+		if err != nil {
+			log.WithField("error", err).Error("server stopped")
+			code = ExitServeError
+		}
+	}
+
+	if err := m.Shutdown(); err != nil && code == ExitOK {
+		code = ExitShutdownError
+	}
+
+	return code
+}
+
+// Shutdown runs every registered hook in reverse order, sharing a single
+// deadline. Hooks keep running after a failure; all errors are returned.
+func (m *Manager) Shutdown() error {
+	m.once.Do(func() {
+		close(m.stopping)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		if err := h.fn(ctx); err != nil {
+			log.WithFields(log.Fields{
+				"component": h.name,
+				"error":     err,
+			}).Error("shutdown failed")
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			continue
+		}
+
+		log.WithField("component", h.name).Info("stopped")
+	}
+
+	return errors.Join(errs...)
+}
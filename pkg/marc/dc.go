@@ -0,0 +1,129 @@
+package marc
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Namespaces of an OAI Dublin Core record.
+const (
+	NamespaceOAIDC = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	NamespaceDC    = "http://purl.org/dc/elements/1.1/"
+)
+
+// DublinCore is a simple Dublin Core description in the oai_dc format.
+type DublinCore struct {
+	XMLName     xml.Name `xml:"oai_dc:dc"`
+	NSOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	NSDC        string   `xml:"xmlns:dc,attr"`
+	Title       []string `xml:"dc:title"`
+	Creator     []string `xml:"dc:creator"`
+	Contributor []string `xml:"dc:contributor"`
+	Publisher   []string `xml:"dc:publisher"`
+	Date        []string `xml:"dc:date"`
+	Type        []string `xml:"dc:type"`
+	Identifier  []string `xml:"dc:identifier"`
+	Language    []string `xml:"dc:language"`
+}
+
+// ToDublinCore maps a bibliographic record onto Dublin Core following the
+// Library of Congress MARC to Dublin Core crosswalk, restricted to the
+// fields this package's users produce.
+func ToDublinCore(r Record) DublinCore {
+	dc := DublinCore{NSOAIDC: NamespaceOAIDC, NSDC: NamespaceDC}
+
+	for _, f := range r.Fields("245") {
+		if title := joinSubfields(f, "abfgknps"); title != "" {
+			dc.Title = append(dc.Title, title)
+		}
+	}
+
+	for _, tag := range []string{"100", "110", "111"} {
+		for _, f := range r.Fields(tag) {
+			if name := TrimPunctuation(f.Subfield("a")); name != "" {
+				dc.Creator = append(dc.Creator, name)
+			}
+		}
+	}
+
+	for _, tag := range []string{"700", "710", "711", "720"} {
+		for _, f := range r.Fields(tag) {
+			if name := TrimPunctuation(f.Subfield("a")); name != "" {
+				dc.Contributor = append(dc.Contributor, name)
+			}
+		}
+	}
+
+	for _, tag := range []string{"260", "264"} {
+		for _, f := range r.Fields(tag) {
+			if tag == "264" && f.Ind2 != "1" {
+				continue
+			}
+			if publisher := TrimPunctuation(f.Subfield("b")); publisher != "" {
+				dc.Publisher = append(dc.Publisher, publisher)
+			}
+			if date := TrimPunctuation(f.Subfield("c")); date != "" {
+				dc.Date = append(dc.Date, date)
+			}
+		}
+	}
+
+	if len(r.Leader) > 6 && (r.Leader[6] == 'a' || r.Leader[6] == 't') {
+		dc.Type = append(dc.Type, "Text")
+	}
+
+	for _, f := range r.Fields("020") {
+		if isbn := ISBN(f); isbn != "" {
+			dc.Identifier = append(dc.Identifier, "URN:ISBN:"+isbn)
+		}
+	}
+
+	if f008 := r.Control("008"); len(f008) >= 38 {
+		if lang := strings.TrimSpace(f008[35:38]); lang != "" && lang != "und" && lang != "|||" {
+			dc.Language = append(dc.Language, lang)
+		}
+	}
+
+	return dc
+}
+
+// joinSubfields joins the values of the subfields with the given codes.
+func joinSubfields(f DataField, codes string) string {
+	parts := make([]string, 0, len(f.Subfields))
+	for _, s := range f.Subfields {
+		if strings.Contains(codes, s.Code) {
+			parts = append(parts, strings.TrimSpace(s.Value))
+		}
+	}
+	return TrimPunctuation(strings.Join(parts, " "))
+}
+
+// ISBN returns the ISBN in $a of an 020 field, without the qualifier that
+// may follow it, as in "9780306406157 (pbk.)".
+func ISBN(f DataField) string {
+	fields := strings.Fields(f.Subfield("a"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// TrimPunctuation strips the ISBD punctuation that MARC cataloguing leaves
+// at the end of subfields, and the brackets around supplied values.
+func TrimPunctuation(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimRight(s, " /:;,=")
+	if strings.HasSuffix(s, ".") && !strings.HasSuffix(s, "..") {
+		// Keep the period of a trailing initial or abbreviation such as "Jr.".
+		if i := strings.LastIndexByte(s, ' '); i < 0 || len(s)-i > 4 {
+			s = strings.TrimSuffix(s, ".")
+		}
+	}
+	s = strings.TrimSpace(s)
+
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+
+	return s
+}
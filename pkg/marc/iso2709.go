@@ -0,0 +1,273 @@
+package marc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ISO 2709 delimiters.
+const (
+	subfieldDelimiter = 0x1F
+	fieldTerminator   = 0x1E
+	recordTerminator  = 0x1D
+)
+
+const (
+	leaderLength    = 24
+	directoryEntry  = 12
+	maxRecordLength = 99999
+	maxFieldLength  = 9999
+)
+
+// defaultLeader is used when a record has no well-formed leader.
+const defaultLeader = "00000nam a2200000 i 4500"
+
+// ErrFormat is wrapped by errors about a malformed record. Readers can
+// skip such a record and continue with the next one.
+var ErrFormat = errors.New("marc: malformed record")
+
+func formatError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrFormat, fmt.Sprintf(format, args...))
+}
+
+// Marshal encodes r in ISO 2709 exchange format with UTF-8 data. The
+// record length, base address and fixed leader positions are computed.
+func Marshal(r Record) ([]byte, error) {
+	var (
+		directory bytes.Buffer
+		data      bytes.Buffer
+	)
+
+	addField := func(tag string, value []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("marc: invalid tag %q", tag)
+		}
+		if len(value) > maxFieldLength {
+			return fmt.Errorf("marc: field %s longer than %d bytes", tag, maxFieldLength)
+		}
+
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(value), data.Len())
+		data.Write(value)
+		return nil
+	}
+
+	for _, f := range r.ControlFields {
+		if err := addField(f.Tag, append([]byte(f.Value), fieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range r.DataFields {
+		var value bytes.Buffer
+		value.WriteString(indicator(f.Ind1))
+		value.WriteString(indicator(f.Ind2))
+		for _, s := range f.Subfields {
+			if len(s.Code) != 1 {
+				return nil, fmt.Errorf("marc: invalid subfield code %q in field %s", s.Code, f.Tag)
+			}
+			value.WriteByte(subfieldDelimiter)
+			value.WriteString(s.Code)
+			value.WriteString(s.Value)
+		}
+		value.WriteByte(fieldTerminator)
+
+		if err := addField(f.Tag, value.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	directory.WriteByte(fieldTerminator)
+	data.WriteByte(recordTerminator)
+
+	base := leaderLength + directory.Len()
+	length := base + data.Len()
+	if length > maxRecordLength {
+		return nil, fmt.Errorf("marc: record longer than %d bytes", maxRecordLength)
+	}
+
+	leader := []byte(r.Leader)
+	if len(leader) != leaderLength {
+		leader = []byte(defaultLeader)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	leader[9] = 'a'
+	leader[10] = '2'
+	leader[11] = '2'
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+
+	out := make([]byte, 0, length)
+	out = append(out, leader...)
+	out = append(out, directory.Bytes()...)
+	out = append(out, data.Bytes()...)
+
+	return out, nil
+}
+
+func indicator(s string) string {
+	if len(s) != 1 {
+		return " "
+	}
+	return s
+}
+
+// Unmarshal decodes one ISO 2709 record. Tags 001-009 are read as control
+// fields, all others as data fields.
+func Unmarshal(data []byte) (Record, error) {
+	var rec Record
+
+	if len(data) < leaderLength+1 {
+		return rec, formatError("record shorter than its leader")
+	}
+
+	length, ok := number(data[0:5])
+	if !ok || length != len(data) {
+		return rec, formatError("record length %q does not match %d bytes", data[0:5], len(data))
+	}
+
+	base, ok := number(data[12:17])
+	if !ok || base <= leaderLength || base > len(data) {
+		return rec, formatError("invalid base address %q", data[12:17])
+	}
+
+	if data[base-1] != fieldTerminator {
+		return rec, formatError("directory is not terminated")
+	}
+
+	rec.Leader = string(data[:leaderLength])
+
+	directory := data[leaderLength : base-1]
+	if len(directory)%directoryEntry != 0 {
+		return rec, formatError("directory length %d is not a multiple of %d", len(directory), directoryEntry)
+	}
+
+	for i := 0; i < len(directory); i += directoryEntry {
+		entry := directory[i : i+directoryEntry]
+		tag := string(entry[0:3])
+
+		fieldLength, ok1 := number(entry[3:7])
+		start, ok2 := number(entry[7:12])
+		if !ok1 || !ok2 || start < 0 || fieldLength < 1 || base+start+fieldLength > len(data) {
+			return rec, formatError("invalid directory entry %q", entry)
+		}
+
+		value := data[base+start : base+start+fieldLength]
+		if value[len(value)-1] != fieldTerminator {
+			return rec, formatError("field %s is not terminated", tag)
+		}
+		value = value[:len(value)-1]
+
+		if isControlTag(tag) {
+			rec.ControlFields = append(rec.ControlFields, ControlField{Tag: tag, Value: string(value)})
+			continue
+		}
+
+		parts := bytes.Split(value, []byte{subfieldDelimiter})
+		indicators := string(parts[0]) + "  "
+
+		field := DataField{Tag: tag, Ind1: indicators[0:1], Ind2: indicators[1:2]}
+		for _, p := range parts[1:] {
+			if len(p) == 0 {
+				continue
+			}
+			field.Subfields = append(field.Subfields, Subfield{Code: string(p[0]), Value: string(p[1:])})
+		}
+
+		rec.DataFields = append(rec.DataFields, field)
+	}
+
+	return rec, nil
+}
+
+// number reads a fixed-width decimal field. Unlike strconv.Atoi it accepts
+// nothing but ASCII digits, so signs and spaces cannot sneak in negative
+// offsets.
+func number(b []byte) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+func isControlTag(tag string) bool {
+	return tag[0] == '0' && tag[1] == '0'
+}
+
+// Reader reads consecutive ISO 2709 records.
+type Reader struct {
+	r *bufio.Reader
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next record, or io.EOF after the last one. A record
+// that was read in full but is malformed yields an error wrapping
+// ErrFormat and reading may continue; other errors are final.
+func (r *Reader) Next() (Record, error) {
+	// Tolerate line breaks between records, as some tools write them.
+	for {
+		b, err := r.r.Peek(1)
+		if err != nil {
+			return Record{}, err
+		}
+		if b[0] != '\n' && b[0] != '\r' {
+			break
+		}
+		r.r.Discard(1)
+	}
+
+	prefix, err := r.r.Peek(5)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+
+	length, ok := number(prefix)
+	if !ok || length < leaderLength+1 {
+		return Record{}, fmt.Errorf("marc: invalid record length %q", prefix)
+	}
+
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Record{}, io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+
+	return Unmarshal(data)
+}
+
+// Writer writes records in ISO 2709 format.
+type Writer struct {
+	w io.Writer
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Write(r Record) error {
+	data, err := Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.w.Write(data)
+	return err
+}
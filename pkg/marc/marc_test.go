@@ -0,0 +1,233 @@
+package marc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func readSample(t *testing.T, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// stripXMLName clears the element name set when decoding XML, so that
+// records decoded from different encodings compare equal.
+func stripXMLName(r Record) Record {
+	r.XMLName = xml.Name{}
+	return r
+}
+
+func TestUnmarshalSample(t *testing.T) {
+	rec, err := Unmarshal(readSample(t, "sample.mrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tag, code, want string
+	}{
+		{"020", "a", "9780306406157 (pbk.)"},
+		{"100", "a", "Tolstoy, Leo,"},
+		{"245", "a", "War and peace /"},
+		{"246", "a", "Война и мир"},
+		{"264", "b", "Signet Classics,"},
+		{"700", "e", "translator."},
+	}
+
+	for _, tt := range tests {
+		fields := rec.Fields(tt.tag)
+		if len(fields) != 1 {
+			t.Fatalf("field %s: got %d fields, want 1", tt.tag, len(fields))
+		}
+		if got := fields[0].Subfield(tt.code); got != tt.want {
+			t.Errorf("%s $%s = %q, want %q", tt.tag, tt.code, got, tt.want)
+		}
+	}
+
+	if got := rec.Control("001"); got != "ocm00012345" {
+		t.Errorf("001 = %q", got)
+	}
+
+	if f := rec.Fields("245")[0]; f.Ind1 != "1" || f.Ind2 != "0" {
+		t.Errorf("245 indicators = %q%q, want 10", f.Ind1, f.Ind2)
+	}
+}
+
+func TestISO2709RoundTrip(t *testing.T) {
+	sample := readSample(t, "sample.mrc")
+
+	rec, err := Unmarshal(sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out, sample) {
+		t.Fatalf("Marshal(Unmarshal(sample)) differs:\n got %q\nwant %q", out, sample)
+	}
+}
+
+func TestMARCXMLMatchesISO2709(t *testing.T) {
+	want, err := Unmarshal(readSample(t, "sample.mrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := NewXMLReader(bytes.NewReader(readSample(t, "sample.xml"))).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(stripXMLName(got), want) {
+		t.Fatalf("MARCXML record differs from ISO 2709 record:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestMARCXMLRoundTrip(t *testing.T) {
+	want, err := Unmarshal(readSample(t, "sample.mrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := NewXMLWriter(&buf)
+	for i := 0; i < 2; i++ {
+		if err := w.Write(want); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewXMLReader(&buf)
+	for i := 0; i < 2; i++ {
+		got, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(stripXMLName(got), want) {
+			t.Fatalf("record %d differs:\n got %+v\nwant %+v", i, got, want)
+		}
+	}
+
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("after last record: %v, want io.EOF", err)
+	}
+}
+
+func TestReaderSkipsMalformedRecord(t *testing.T) {
+	sample := readSample(t, "sample.mrc")
+
+	broken := append([]byte(nil), sample...)
+	broken[len(broken)-2] = 'x' // overwrite the terminator of the last field
+
+	stream := bytes.Join([][]byte{sample, broken, sample}, []byte("\n"))
+	r := NewReader(bytes.NewReader(stream))
+
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("record 1: %v", err)
+	}
+	if _, err := r.Next(); !errors.Is(err, ErrFormat) {
+		t.Fatalf("record 2: %v, want ErrFormat", err)
+	}
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("record 3: %v", err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("after last record: %v, want io.EOF", err)
+	}
+}
+
+func TestToDublinCore(t *testing.T) {
+	rec, err := Unmarshal(readSample(t, "sample.mrc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc := ToDublinCore(rec)
+
+	tests := []struct {
+		element string
+		got     []string
+		want    []string
+	}{
+		{"title", dc.Title, []string{"War and peace"}},
+		{"creator", dc.Creator, []string{"Tolstoy, Leo"}},
+		{"contributor", dc.Contributor, []string{"Dunnigan, Ann"}},
+		{"publisher", dc.Publisher, []string{"Signet Classics"}},
+		{"date", dc.Date, []string{"1968"}},
+		{"type", dc.Type, []string{"Text"}},
+		{"identifier", dc.Identifier, []string{"URN:ISBN:9780306406157"}},
+		{"language", dc.Language, []string{"eng"}},
+	}
+
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("dc:%s = %q, want %q", tt.element, tt.got, tt.want)
+		}
+	}
+}
+
+func TestTrimPunctuation(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"War and peace /", "War and peace"},
+		{"New York :", "New York"},
+		{"Signet Classics,", "Signet Classics"},
+		{"1968.", "1968"},
+		{"[1968]", "1968"},
+		{"Smith, J.", "Smith, J."},
+		{"King, Martin Luther, Jr.", "King, Martin Luther, Jr."},
+		{"Tolstoy, Leo.", "Tolstoy, Leo"},
+	}
+
+	for _, tt := range tests {
+		if got := TrimPunctuation(tt.input); got != tt.want {
+			t.Errorf("TrimPunctuation(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalRejectsSignedNumbers(t *testing.T) {
+	sample := readSample(t, "sample.mrc")
+
+	tests := []struct {
+		name   string
+		offset int
+		value  string
+	}{
+		{"negative field start", leaderLength + 7, "-9999"},
+		{"signed field start", leaderLength + 7, "+0000"},
+		{"negative field length", leaderLength + 3, "-001"},
+		{"spaced field length", leaderLength + 3, " 001"},
+		{"signed base address", 12, "+0" + string(sample[14:17])},
+		{"signed record length", 0, "+" + string(sample[1:5])},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crafted := append([]byte(nil), sample...)
+			copy(crafted[tt.offset:], tt.value)
+
+			if _, err := Unmarshal(crafted); !errors.Is(err, ErrFormat) {
+				t.Fatalf("Unmarshal = %v, want ErrFormat", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+// Package marc reads and writes MARC 21 bibliographic records in ISO 2709
+// and MARCXML, and maps them onto simple Dublin Core.
+package marc
+
+import "encoding/xml"
+
+// Namespace is the MARCXML namespace.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+// Record is a MARC 21 record: a leader followed by control fields (tags
+// 001-009) and data fields, in tag order.
+type Record struct {
+	XMLName       xml.Name       `xml:"record"`
+	Leader        string         `xml:"leader"`
+	ControlFields []ControlField `xml:"controlfield"`
+	DataFields    []DataField    `xml:"datafield"`
+}
+
+type ControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type DataField struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+type Subfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// AddControl appends a control field.
+func (r *Record) AddControl(tag, value string) {
+	r.ControlFields = append(r.ControlFields, ControlField{Tag: tag, Value: value})
+}
+
+// AddData appends a data field built from alternating subfield codes and
+// values. Empty values are dropped, and so is a field left without
+// subfields.
+func (r *Record) AddData(tag string, ind1, ind2 byte, codeValues ...string) {
+	field := DataField{Tag: tag, Ind1: string(ind1), Ind2: string(ind2)}
+	for i := 0; i+1 < len(codeValues); i += 2 {
+		if codeValues[i+1] != "" {
+			field.Subfields = append(field.Subfields, Subfield{Code: codeValues[i], Value: codeValues[i+1]})
+		}
+	}
+
+	if len(field.Subfields) > 0 {
+		r.DataFields = append(r.DataFields, field)
+	}
+}
+
+// Control returns the value of the first control field with tag.
+func (r Record) Control(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields returns the data fields with tag.
+func (r Record) Fields(tag string) []DataField {
+	fields := make([]DataField, 0)
+	for _, f := range r.DataFields {
+		if f.Tag == tag {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// Subfield returns the value of the first subfield with code.
+func (f DataField) Subfield(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return s.Value
+		}
+	}
+	return ""
+}
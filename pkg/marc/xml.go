@@ -0,0 +1,92 @@
+package marc
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// XMLWriter streams records as a MARCXML collection. Close must be called
+// to end the collection.
+type XMLWriter struct {
+	enc     *xml.Encoder
+	started bool
+}
+
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	return &XMLWriter{enc: xml.NewEncoder(w)}
+}
+
+var collection = xml.StartElement{
+	Name: xml.Name{Local: "collection"},
+	Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: Namespace}},
+}
+
+func (x *XMLWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+
+	if err := x.enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return err
+	}
+	return x.enc.EncodeToken(collection)
+}
+
+func (x *XMLWriter) Write(r Record) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	return x.enc.Encode(r)
+}
+
+// Close ends the collection and flushes the output. It does not close the
+// underlying writer.
+func (x *XMLWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if err := x.enc.EncodeToken(collection.End()); err != nil {
+		return err
+	}
+	return x.enc.Flush()
+}
+
+// XMLReader reads records from a MARCXML document, either a collection or
+// a single record.
+type XMLReader struct {
+	dec *xml.Decoder
+}
+
+func NewXMLReader(r io.Reader) *XMLReader {
+	return &XMLReader{dec: xml.NewDecoder(r)}
+}
+
+// Next returns the next record, or io.EOF after the last one. A record
+// element that cannot be decoded yields an error wrapping ErrFormat and
+// reading may continue; malformed XML outside records is final.
+func (x *XMLReader) Next() (Record, error) {
+	for {
+		tok, err := x.dec.Token()
+		if err != nil {
+			return Record{}, err
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "record" {
+			continue
+		}
+
+		var rec Record
+		if err := x.dec.DecodeElement(&rec, &start); err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				return Record{}, err
+			}
+			return Record{}, formatError("%s", err)
+		}
+
+		return rec, nil
+	}
+}
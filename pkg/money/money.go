@@ -0,0 +1,126 @@
+// Package money handles amounts of a currency with two decimal places.
+// Amounts are whole numbers of cents, so sums never pick up float
+// rounding errors; their text form is a plain decimal such as "12.50".
+package money
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxAmount bounds parsed amounts well below the int64 range, so that
+// sums of amounts cannot overflow.
+const MaxAmount = Amount(1e15)
+
+var (
+	ErrSyntax = errors.New("money: amount must be a decimal with at most two fractional digits")
+	ErrRange  = errors.New("money: amount out of range")
+)
+
+// Amount is a signed number of cents.
+type Amount int64
+
+// Parse reads a decimal amount with an optional sign and at most two
+// fractional digits, such as "3", "-0.5" or "12.50". Exponents,
+// separators and currency symbols are rejected.
+func Parse(s string) (Amount, error) {
+	neg := false
+	switch {
+	case strings.HasPrefix(s, "-"):
+		neg, s = true, s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+
+	whole, frac, hasPoint := strings.Cut(s, ".")
+	if whole == "" && frac == "" || len(frac) > 2 || hasPoint && frac == "" {
+		return 0, ErrSyntax
+	}
+	if !digits(whole) || !digits(frac) {
+		return 0, ErrSyntax
+	}
+
+	var units int64
+	if whole != "" {
+		var err error
+		units, err = strconv.ParseInt(whole, 10, 64)
+		if err != nil || units > int64(MaxAmount/100) {
+			return 0, ErrRange
+		}
+	}
+
+	frac += strings.Repeat("0", 2-len(frac))
+	cents, _ := strconv.ParseInt(frac, 10, 64)
+
+	a := Amount(units*100 + cents)
+	if a > MaxAmount {
+		return 0, ErrRange
+	}
+	if neg {
+		a = -a
+	}
+	return a, nil
+}
+
+// String formats a with exactly two fractional digits.
+func (a Amount) String() string {
+	sign := ""
+	n := int64(a)
+	if n < 0 {
+		if n == math.MinInt64 {
+			return "-92233720368547758.08"
+		}
+		sign, n = "-", -n
+	}
+
+	cents := strconv.FormatInt(n%100, 10)
+	if len(cents) == 1 {
+		cents = "0" + cents
+	}
+	return sign + strconv.FormatInt(n/100, 10) + "." + cents
+}
+
+// MarshalText encodes a as its decimal string.
+func (a Amount) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses a decimal string with Parse.
+func (a *Amount) UnmarshalText(b []byte) error {
+	v, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// MarshalJSON encodes a as a JSON string, which clients read without
+// converting to binary floating point.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts the amount as a JSON string or a JSON number. A
+// number is parsed from its literal text, never through a float.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return a.UnmarshalText([]byte(s))
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
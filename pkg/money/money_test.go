@@ -0,0 +1,94 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		amount Amount
+		err    error
+	}{
+		{"0", 0, nil},
+		{"3", 300, nil},
+		{"0.5", 50, nil},
+		{".25", 25, nil},
+		{"12.50", 1250, nil},
+		{"+1.01", 101, nil},
+		{"-0.05", -5, nil},
+		{"10000000000000", 1000000000000000, nil},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{".", 0, ErrSyntax},
+		{"1.", 0, ErrSyntax},
+		{"1.234", 0, ErrSyntax},
+		{"1e3", 0, ErrSyntax},
+		{"1,50", 0, ErrSyntax},
+		{"$5", 0, ErrSyntax},
+		{" 5", 0, ErrSyntax},
+		{"--5", 0, ErrSyntax},
+		{"10000000000001", 0, ErrRange},
+		{"99999999999999999999", 0, ErrRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if err == nil && got != tt.amount {
+				t.Errorf("Parse(%q) = %d, want %d", tt.input, got, tt.amount)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{50, "0.50"},
+		{1250, "12.50"},
+		{-1, "-0.01"},
+		{-1250, "-12.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		A Amount `json:"a"`
+		B Amount `json:"b"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": "0.10", "b": 0.20}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.A != 10 || v.B != 20 {
+		t.Errorf("decoded %d and %d, want 10 and 20", v.A, v.B)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": 0.001}`), &v); !errors.Is(err, ErrSyntax) {
+		t.Errorf("decoding 0.001: error = %v, want %v", err, ErrSyntax)
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"a":"0.10","b":"0.20"}` {
+		t.Errorf("encoded %s", b)
+	}
+}
@@ -0,0 +1,276 @@
+// Package scheduler runs jobs on cron schedules inside the service. When
+// several replicas share a database, a Leader makes sure only one of them
+// runs the jobs.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"lib/pkg/cron"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Run results.
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultTimeout = "timeout"
+	ResultSkipped = "skipped"
+)
+
+// Job is a task and its schedule. Each run gets a random delay of up to
+// Jitter after its scheduled time, so that replicas and jobs sharing a
+// schedule do not all hit the database at once, and is cancelled after
+// Timeout. A zero Timeout lets a run take as long as it needs.
+type Job struct {
+	Name     string
+	Schedule *cron.Schedule
+	Timeout  time.Duration
+	Jitter   time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Leader elects the replica that runs the jobs.
+type Leader interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// History records the runs of jobs. CloseStale fails the runs left
+// unfinished by a replica that stopped leading mid-run, except those
+// still running here, and returns how many it closed.
+type History interface {
+	Start(ctx context.Context, job string) (int64, error)
+	Finish(ctx context.Context, id int64, result string, runErr error) error
+	CloseStale(ctx context.Context, running []int64) (int64, error)
+}
+
+// Observer receives run metrics.
+type Observer interface {
+	ObserveJob(job, result string, d time.Duration)
+	SetLeader(leader bool)
+}
+
+// Scheduler runs jobs until stopped. Runs of one job never overlap: a run
+// that is still going at the next scheduled time makes that time skip.
+type Scheduler struct {
+	jobs     []Job
+	leader   Leader
+	history  History
+	observer Observer
+
+	now    func() time.Time
+	jitter func(max time.Duration) time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu guards leading and running, the runs of this replica that have
+	// not finished.
+	mu      sync.Mutex
+	leading bool
+	running map[int64]bool
+}
+
+func New(leader Leader, history History, observer Observer, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		jobs:     jobs,
+		leader:   leader,
+		history:  history,
+		observer: observer,
+		now:      time.Now,
+		jitter:   randomJitter,
+		running:  make(map[int64]bool),
+	}
+}
+
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+// Start launches one loop per job.
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	for {
+		next := job.Schedule.Next(s.now())
+		if next.IsZero() {
+			log.WithField("job", job.Name).Warn("schedule never fires")
+			return
+		}
+
+		timer := time.NewTimer(next.Add(s.jitter(job.Jitter)).Sub(s.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.runOnce(ctx, job)
+	}
+}
+
+// runOnce runs job if this replica leads, recording the run.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) string {
+	logger := log.WithField("job", job.Name)
+
+	leader, err := s.leader.TryAcquire(ctx)
+	if err != nil {
+		logger.WithField("error", err).Error("leader election failed")
+	}
+	s.observer.SetLeader(leader)
+
+	if !leader {
+		s.mu.Lock()
+		s.leading = false
+		s.mu.Unlock()
+
+		s.observer.ObserveJob(job.Name, ResultSkipped, 0)
+		return ResultSkipped
+	}
+
+	id := s.start(ctx, job.Name)
+	if id != 0 {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, id)
+			s.mu.Unlock()
+		}()
+	}
+
+	runCtx := ctx
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	start := s.now()
+	runErr := run(runCtx, job)
+	elapsed := s.now().Sub(start)
+
+	result := ResultSuccess
+	switch {
+	case runErr == nil:
+	case errors.Is(runErr, context.DeadlineExceeded) || runCtx.Err() == context.DeadlineExceeded:
+		result = ResultTimeout
+	default:
+		result = ResultFailure
+	}
+
+	s.observer.ObserveJob(job.Name, result, elapsed)
+
+	if result != ResultSuccess {
+		logger.WithFields(log.Fields{"result": result, "error": runErr}).Error("job failed")
+	}
+
+	if id != 0 {
+		// The run is recorded even when it was cancelled by shutdown.
+		finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+
+		if err := s.history.Finish(finishCtx, id, result, runErr); err != nil {
+			logger.WithField("error", err).Error("recording job result failed")
+		}
+	}
+
+	return result
+}
+
+// start records the start of a run and returns its ID, or 0 if it could
+// not be recorded. On becoming leader it first closes the runs a previous
+// leader left unfinished.
+func (s *Scheduler) start(ctx context.Context, job string) int64 {
+	logger := log.WithField("job", job)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.leading {
+		running := make([]int64, 0, len(s.running))
+		for id := range s.running {
+			running = append(running, id)
+		}
+
+		closed, err := s.history.CloseStale(ctx, running)
+		if err != nil {
+			logger.WithField("error", err).Error("closing stale job runs failed")
+		} else {
+			s.leading = true
+			if closed > 0 {
+				logger.WithField("runs", closed).Warn("closed stale job runs")
+			}
+		}
+	}
+
+	id, err := s.history.Start(ctx, job)
+	if err != nil {
+		logger.WithField("error", err).Error("recording job start failed")
+		return 0
+	}
+
+	s.running[id] = true
+	return id
+}
+
+// run calls job, turning a panic into an error so that the run is
+// recorded as a failure.
+func run(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithFields(log.Fields{"job": job.Name, "panic": r, "stack": string(debug.Stack())}).Error("job panicked")
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// Stop cancels running jobs, waits for them to return or for ctx to
+// expire, and gives up leadership.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	s.leading = false
+	s.mu.Unlock()
+
+	s.observer.SetLeader(false)
+	return s.leader.Release(ctx)
+}
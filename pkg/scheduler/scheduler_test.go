@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"lib/pkg/cron"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeLeader struct {
+	leader bool
+}
+
+func (l *fakeLeader) TryAcquire(context.Context) (bool, error) { return l.leader, nil }
+func (l *fakeLeader) Release(context.Context) error            { l.leader = false; return nil }
+
+type fakeHistory struct {
+	mu      sync.Mutex
+	started []string
+	results []string
+	closed  [][]int64
+}
+
+func (h *fakeHistory) Start(_ context.Context, job string) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.started = append(h.started, job)
+	return int64(len(h.started)), nil
+}
+
+func (h *fakeHistory) Finish(_ context.Context, _ int64, result string, _ error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results = append(h.results, result)
+	return nil
+}
+
+func (h *fakeHistory) CloseStale(_ context.Context, running []int64) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = append(h.closed, running)
+	return 0, nil
+}
+
+type fakeObserver struct {
+	mu      sync.Mutex
+	results []string
+}
+
+func (o *fakeObserver) ObserveJob(_, result string, _ time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results = append(o.results, result)
+}
+
+func (o *fakeObserver) SetLeader(bool) {}
+
+func TestRunOnce(t *testing.T) {
+	errTask := errors.New("task failed")
+
+	tests := []struct {
+		name    string
+		leader  bool
+		timeout time.Duration
+		run     func(ctx context.Context) error
+		result  string
+	}{
+		{"success", true, 0, func(context.Context) error { return nil }, ResultSuccess},
+		{"failure", true, 0, func(context.Context) error { return errTask }, ResultFailure},
+		{"panic", true, 0, func(context.Context) error { panic("task panicked") }, ResultFailure},
+		{"timeout", true, time.Millisecond, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, ResultTimeout},
+		{"follower", false, 0, func(context.Context) error {
+			t.Error("follower ran the job")
+			return nil
+		}, ResultSkipped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, observer := &fakeHistory{}, &fakeObserver{}
+			s := New(&fakeLeader{leader: tt.leader}, history, observer)
+
+			job := Job{Name: "job", Schedule: cron.MustParse("@hourly"), Timeout: tt.timeout, Run: tt.run}
+			if got := s.runOnce(context.Background(), job); got != tt.result {
+				t.Fatalf("runOnce = %q, want %q", got, tt.result)
+			}
+
+			if len(observer.results) != 1 || observer.results[0] != tt.result {
+				t.Errorf("observed %v, want [%s]", observer.results, tt.result)
+			}
+
+			wantRecorded := 1
+			if !tt.leader {
+				wantRecorded = 0
+			}
+			if len(history.started) != wantRecorded || len(history.results) != wantRecorded {
+				t.Errorf("recorded %d starts and %d results, want %d", len(history.started), len(history.results), wantRecorded)
+			}
+		})
+	}
+}
+
+func TestRunOnceClosesStaleRunsOnAcquisition(t *testing.T) {
+	history, observer := &fakeHistory{}, &fakeObserver{}
+	leader := &fakeLeader{leader: true}
+	s := New(leader, history, observer)
+
+	job := Job{Name: "job", Schedule: cron.MustParse("@hourly"), Run: func(context.Context) error { return nil }}
+
+	s.runOnce(context.Background(), job)
+	s.runOnce(context.Background(), job)
+	if len(history.closed) != 1 {
+		t.Fatalf("closed stale runs %d times while leading, want 1", len(history.closed))
+	}
+
+	leader.leader = false
+	s.runOnce(context.Background(), job)
+	leader.leader = true
+
+	// A run still going here when leadership comes back is kept open.
+	s.running[42] = true
+	s.runOnce(context.Background(), job)
+	if len(history.closed) != 2 {
+		t.Fatalf("closed stale runs %d times after reacquiring, want 2", len(history.closed))
+	}
+	if running := history.closed[1]; len(running) != 1 || running[0] != 42 {
+		t.Errorf("kept %v open, want [42]", running)
+	}
+}
+
+func TestSchedulerRunsAtScheduledTime(t *testing.T) {
+	history, observer := &fakeHistory{}, &fakeObserver{}
+	ran := make(chan struct{}, 1)
+
+	s := New(&fakeLeader{leader: true}, history, observer, Job{
+		Name:     "job",
+		Schedule: cron.MustParse("* * * * *"),
+		Run: func(context.Context) error {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+			return nil
+		},
+	})
+
+	// Pretend the next minute starts in 10ms.
+	base := time.Now()
+	offset := time.Minute - time.Duration(base.Second())*time.Second - time.Duration(base.Nanosecond()) - 10*time.Millisecond
+	s.now = func() time.Time { return time.Now().Add(offset) }
+
+	s.Start()
+	defer s.Stop(context.Background())
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not run")
+	}
+}